@@ -2,14 +2,14 @@
 
 import { useEffect, useState } from "react";
 import { Plus, Pencil, Trash2, Tag, X } from "lucide-react";
-import { getCategories, createCategory, updateCategory, deleteCategory, Category } from "@/lib/api";
+import { getCategories, createCategory, updateCategory, deleteCategory, sortCategoryTree, Category } from "@/lib/api";
 
 export default function CategoriesPage() {
   const [categories, setCategories] = useState<Category[]>([]);
   const [loading, setLoading] = useState(true);
   const [isModalOpen, setIsModalOpen] = useState(false);
   const [editingCategory, setEditingCategory] = useState<Category | null>(null);
-  const [formData, setFormData] = useState({ name: "" });
+  const [formData, setFormData] = useState({ name: "", parent_id: "" });
   const [saving, setSaving] = useState(false);
 
   async function fetchData() {
@@ -29,13 +29,13 @@ export default function CategoriesPage() {
 
   const openCreateModal = () => {
     setEditingCategory(null);
-    setFormData({ name: "" });
+    setFormData({ name: "", parent_id: "" });
     setIsModalOpen(true);
   };
 
   const openEditModal = (category: Category) => {
     setEditingCategory(category);
-    setFormData({ name: category.name });
+    setFormData({ name: category.name, parent_id: category.parent_id || "" });
     setIsModalOpen(true);
   };
 
@@ -45,6 +45,7 @@ export default function CategoriesPage() {
 
     const data = new FormData();
     data.append("name", formData.name);
+    data.append("parent_id", formData.parent_id);
 
     try {
       if (editingCategory) {
@@ -61,6 +62,16 @@ export default function CategoriesPage() {
     }
   };
 
+  // Als Elternkategorie kommen beim Bearbeiten weder die Kategorie selbst
+  // noch ihre Unterkategorien in Frage.
+  const parentOptions = sortCategoryTree(categories).filter(({ category }) => {
+    if (!editingCategory) return true;
+    for (let current: Category | undefined = category; current; current = categories.find((c) => c.id === current?.parent_id)) {
+      if (current.id === editingCategory.id) return false;
+    }
+    return true;
+  });
+
   const handleDelete = async (category: Category) => {
     if (!confirm(`Kategorie "${category.name}" wirklich löschen?`)) return;
     try {
@@ -114,17 +125,18 @@ export default function CategoriesPage() {
         </div>
       ) : (
         <div className="bg-white rounded-2xl border border-black/[0.06] shadow-sm divide-y divide-black/[0.06]">
-          {categories.map((category) => (
+          {sortCategoryTree(categories).map(({ category, depth }) => (
             <div 
               key={category.id}
               className="px-5 py-4 flex items-center gap-4 hover:bg-black/[0.01] transition-colors"
+              style={{ paddingLeft: `${20 + depth * 32}px` }}
             >
               <div className="w-10 h-10 rounded-xl bg-black/[0.04] flex items-center justify-center">
                 <Tag className="w-5 h-5 text-black/30" />
               </div>
               <div className="flex-1 min-w-0">
                 <p className="text-[15px] font-medium text-black">{category.name}</p>
-                <span className="text-[11px] font-mono text-black/30">{category.id}</span>
+                <span className="text-[11px] font-mono text-black/30">{category.slug}</span>
               </div>
               <div className="flex items-center gap-2">
                 <button
@@ -184,6 +196,21 @@ export default function CategoriesPage() {
                     className="w-full px-4 py-3 rounded-xl bg-black/[0.03] border border-black/[0.06] text-[15px] text-black placeholder:text-black/30 focus:outline-none focus:border-black/20 focus:ring-0 transition-colors"
                   />
                 </div>
+                <div>
+                  <label className="block text-[13px] font-medium text-black/60 mb-2">Elternkategorie</label>
+                  <select
+                    value={formData.parent_id}
+                    onChange={(e) => setFormData({ ...formData, parent_id: e.target.value })}
+                    className="w-full px-4 py-3 rounded-xl bg-black/[0.03] border border-black/[0.06] text-[15px] text-black focus:outline-none focus:border-black/20 focus:ring-0 transition-colors"
+                  >
+                    <option value="">Keine</option>
+                    {parentOptions.map(({ category, depth }) => (
+                      <option key={category.id} value={category.id}>
+                        {"\u00a0\u00a0".repeat(depth)}{category.name}
+                      </option>
+                    ))}
+                  </select>
+                </div>
                 <div className="flex gap-3 pt-4">
                   <button
                     type="button"
@@ -0,0 +1,74 @@
+"use client";
+
+import { useState } from "react";
+import { useRouter } from "next/navigation";
+import { login } from "@/lib/api";
+
+export default function LoginPage() {
+  const router = useRouter();
+  const [formData, setFormData] = useState({ identifier: "", password: "" });
+  const [error, setError] = useState<string | null>(null);
+  const [loading, setLoading] = useState(false);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    setError(null);
+
+    try {
+      await login(formData.identifier, formData.password);
+      router.replace("/");
+    } catch (error) {
+      setError(error instanceof Error ? error.message : "Anmeldung fehlgeschlagen");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="bg-white rounded-2xl border border-black/[0.06] shadow-sm p-8 w-full max-w-md">
+      <div className="flex items-center gap-3 mb-8">
+        <div className="w-10 h-10 rounded-xl bg-black flex items-center justify-center shadow-lg">
+          <span className="text-white font-semibold text-lg">P</span>
+        </div>
+        <div>
+          <h1 className="text-[15px] font-semibold tracking-tight text-black">Portfolio</h1>
+          <p className="text-[11px] text-black/40 font-medium">Admin Panel</p>
+        </div>
+      </div>
+
+      <form onSubmit={handleSubmit} className="space-y-5">
+        <div>
+          <label className="block text-[13px] font-medium text-black/60 mb-2">E-Mail oder Benutzername</label>
+          <input
+            type="text"
+            value={formData.identifier}
+            onChange={(e) => setFormData({ ...formData, identifier: e.target.value })}
+            autoComplete="username"
+            required
+            className="w-full px-4 py-3 rounded-xl bg-black/[0.03] border border-black/[0.06] text-[15px] text-black placeholder:text-black/30 focus:outline-none focus:border-black/20 focus:ring-0 transition-colors"
+          />
+        </div>
+        <div>
+          <label className="block text-[13px] font-medium text-black/60 mb-2">Passwort</label>
+          <input
+            type="password"
+            value={formData.password}
+            onChange={(e) => setFormData({ ...formData, password: e.target.value })}
+            autoComplete="current-password"
+            required
+            className="w-full px-4 py-3 rounded-xl bg-black/[0.03] border border-black/[0.06] text-[15px] text-black placeholder:text-black/30 focus:outline-none focus:border-black/20 focus:ring-0 transition-colors"
+          />
+        </div>
+        {error && <p className="text-[13px] text-red-500">{error}</p>}
+        <button
+          type="submit"
+          disabled={loading}
+          className="w-full px-5 py-3 text-[14px] font-medium text-white bg-black rounded-xl hover:bg-black/80 transition-colors disabled:opacity-50"
+        >
+          {loading ? "Anmelden..." : "Anmelden"}
+        </button>
+      </form>
+    </div>
+  );
+}
@@ -11,7 +11,7 @@ export default function UsersPage() {
   const [loading, setLoading] = useState(true);
   const [isModalOpen, setIsModalOpen] = useState(false);
   const [editingUser, setEditingUser] = useState<User | null>(null);
-  const [formData, setFormData] = useState({ name: "", email: "" });
+  const [formData, setFormData] = useState({ name: "", email: "", password: "" });
   const [avatarFile, setAvatarFile] = useState<File | null>(null);
   const [saving, setSaving] = useState(false);
 
@@ -32,14 +32,14 @@ export default function UsersPage() {
 
   const openCreateModal = () => {
     setEditingUser(null);
-    setFormData({ name: "", email: "" });
+    setFormData({ name: "", email: "", password: "" });
     setAvatarFile(null);
     setIsModalOpen(true);
   };
 
   const openEditModal = (user: User) => {
     setEditingUser(user);
-    setFormData({ name: user.name, email: user.email });
+    setFormData({ name: user.name, email: user.email, password: "" });
     setAvatarFile(null);
     setIsModalOpen(true);
   };
@@ -51,6 +51,9 @@ export default function UsersPage() {
     const data = new FormData();
     data.append("name", formData.name);
     data.append("email", formData.email);
+    if (formData.password) {
+      data.append("password", formData.password);
+    }
     if (avatarFile) {
       data.append("avatar", avatarFile);
     }
@@ -203,6 +206,17 @@ export default function UsersPage() {
                     className="w-full px-4 py-3 rounded-xl bg-black/[0.03] border border-black/[0.06] text-[15px] text-black placeholder:text-black/30 focus:outline-none focus:border-black/20 focus:ring-0 transition-colors"
                   />
                 </div>
+                <div>
+                  <label className="block text-[13px] font-medium text-black/60 mb-2">Passwort</label>
+                  <input
+                    type="password"
+                    value={formData.password}
+                    onChange={(e) => setFormData({ ...formData, password: e.target.value })}
+                    placeholder={editingUser ? "Leer lassen, um es nicht zu ändern" : "Für den Login (optional)"}
+                    autoComplete="new-password"
+                    className="w-full px-4 py-3 rounded-xl bg-black/[0.03] border border-black/[0.06] text-[15px] text-black placeholder:text-black/30 focus:outline-none focus:border-black/20 focus:ring-0 transition-colors"
+                  />
+                </div>
                 <ImageUpload
                   currentImage={editingUser?.avatar}
                   onFileSelect={setAvatarFile}
@@ -0,0 +1,41 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { usePathname, useRouter } from "next/navigation";
+import Sidebar from "@/components/Sidebar";
+import { getSession } from "@/lib/session";
+
+// Ohne Session geht es direkt zum Login, die Login-Seite selbst wird ohne
+// Sidebar angezeigt.
+export default function AppShell({ children }: { children: React.ReactNode }) {
+  const pathname = usePathname();
+  const router = useRouter();
+  const [ready, setReady] = useState(false);
+  const isLogin = pathname === "/login";
+
+  useEffect(() => {
+    if (!isLogin && !getSession()) {
+      router.replace("/login");
+      return;
+    }
+    setReady(true);
+  }, [isLogin, router]);
+
+  if (isLogin) {
+    return <main className="min-h-screen flex items-center justify-center p-4">{children}</main>;
+  }
+  if (!ready) {
+    return null;
+  }
+
+  return (
+    <div className="flex min-h-screen">
+      <Sidebar />
+      <main className="flex-1 ml-64 p-10">
+        <div className="max-w-6xl mx-auto">
+          {children}
+        </div>
+      </main>
+    </div>
+  );
+}
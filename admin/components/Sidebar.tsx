@@ -1,7 +1,7 @@
 "use client";
 
 import Link from "next/link";
-import { usePathname } from "next/navigation";
+import { usePathname, useRouter } from "next/navigation";
 import { 
   LayoutDashboard, 
   Users, 
@@ -9,7 +9,9 @@ import {
   Code2, 
   FolderKanban,
   Tag,
+  LogOut,
 } from "lucide-react";
+import { logout } from "@/lib/api";
 
 const navigation = [
   { name: "Dashboard", href: "/", icon: LayoutDashboard },
@@ -22,6 +24,16 @@ const navigation = [
 
 export default function Sidebar() {
   const pathname = usePathname();
+  const router = useRouter();
+
+  const handleLogout = async () => {
+    try {
+      await logout();
+    } catch (error) {
+      console.error("Failed to logout:", error);
+    }
+    router.replace("/login");
+  };
 
   return (
     <aside className="fixed left-0 top-0 h-screen w-64 glass border-r border-black/5 flex flex-col z-50">
@@ -75,6 +87,13 @@ export default function Sidebar() {
           </span>
           <span className="text-[11px] font-medium text-black/40">API Verbunden</span>
         </div>
+        <button
+          onClick={handleLogout}
+          className="w-full flex items-center gap-3 px-3 py-2.5 rounded-xl text-[13px] font-medium text-black/60 hover:text-black hover:bg-black/5 transition-all"
+        >
+          <LogOut className="w-[18px] h-[18px]" strokeWidth={1.5} />
+          Abmelden
+        </button>
       </div>
     </aside>
   );
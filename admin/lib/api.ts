@@ -1,4 +1,102 @@
-const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
+import { getSession, setSession, clearSession, Session } from "./session";
+
+export const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
+
+// ApiError ist eine Fehlerantwort der API (application/problem+json).
+export class ApiError extends Error {
+  status: number;
+
+  constructor(status: number, message: string) {
+    super(message);
+    this.status = status;
+  }
+}
+
+// request schickt den Request mit dem Access Token der Session. Ohne gültige
+// Session lehnt die API alle schreibenden Requests mit 401 ab, dann geht es
+// zurück zum Login.
+async function request(path: string, init: RequestInit = {}): Promise<Response> {
+  const headers = new Headers(init.headers);
+  const session = await currentSession();
+  if (session) {
+    headers.set("Authorization", `Bearer ${session.access_token}`);
+  }
+
+  const res = await fetch(`${API_URL}${path}`, { cache: "no-store", ...init, headers });
+  if (res.status === 401 && typeof window !== "undefined") {
+    clearSession();
+    window.location.href = "/login";
+  }
+  if (!res.ok) {
+    const problem = await res.json().catch(() => null);
+    throw new ApiError(res.status, problem?.detail || res.statusText);
+  }
+  return res;
+}
+
+// Auth
+async function authRequest(path: string, data: FormData): Promise<Session> {
+  const res = await fetch(`${API_URL}${path}`, { method: "POST", body: data, cache: "no-store" });
+  const body = await res.json().catch(() => null);
+  if (!res.ok) {
+    throw new ApiError(res.status, body?.detail || res.statusText);
+  }
+  return body as Session;
+}
+
+// login meldet sich mit E-Mail oder, für den Admin aus den
+// Umgebungsvariablen, mit Benutzername an.
+export async function login(identifier: string, password: string): Promise<Session> {
+  const data = new FormData();
+  data.append(identifier.includes("@") ? "email" : "username", identifier);
+  data.append("password", password);
+  const session = await authRequest("/auth/login", data);
+  setSession(session);
+  return session;
+}
+
+export async function logout(): Promise<void> {
+  try {
+    await request("/auth/logout", { method: "POST" });
+  } finally {
+    clearSession();
+  }
+}
+
+let refreshing: Promise<Session | null> | null = null;
+
+// currentSession erneuert einen abgelaufenen Access Token mit dem Refresh
+// Token, solange dieser noch gültig ist.
+async function currentSession(): Promise<Session | null> {
+  const session = getSession();
+  if (!session || new Date(session.expires_at) > new Date()) {
+    return session;
+  }
+  if (new Date(session.refresh_expires_at) <= new Date()) {
+    clearSession();
+    return null;
+  }
+
+  // Der Refresh Token ist nur einmal gültig, parallele Requests warten
+  // daher auf dieselbe Erneuerung.
+  if (!refreshing) {
+    const data = new FormData();
+    data.append("refresh_token", session.refresh_token);
+    refreshing = authRequest("/auth/refresh", data)
+      .then((refreshed) => {
+        setSession(refreshed);
+        return refreshed;
+      })
+      .catch(() => {
+        clearSession();
+        return null;
+      })
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+}
 
 // Types
 export interface User {
@@ -35,6 +133,10 @@ export interface Language {
 export interface Category {
   id: string;
   name: string;
+  slug: string;
+  description?: string;
+  parent_id?: string | null;
+  children?: Category[];
   created_at: string;
   updated_at: string;
 }
@@ -53,17 +155,17 @@ export interface Project {
 
 // Users
 export async function getUsers(): Promise<User[]> {
-  const res = await fetch(`${API_URL}/users`, { cache: "no-store" });
+  const res = await request(`/users`);
   return res.json();
 }
 
 export async function getUser(id: string): Promise<User> {
-  const res = await fetch(`${API_URL}/users/${id}`, { cache: "no-store" });
+  const res = await request(`/users/${id}`);
   return res.json();
 }
 
 export async function createUser(data: FormData): Promise<User> {
-  const res = await fetch(`${API_URL}/users`, {
+  const res = await request(`/users`, {
     method: "POST",
     body: data,
   });
@@ -71,7 +173,7 @@ export async function createUser(data: FormData): Promise<User> {
 }
 
 export async function updateUser(id: string, data: FormData): Promise<User> {
-  const res = await fetch(`${API_URL}/users/${id}`, {
+  const res = await request(`/users/${id}`, {
     method: "PUT",
     body: data,
   });
@@ -80,17 +182,17 @@ export async function updateUser(id: string, data: FormData): Promise<User> {
 
 // Blogs
 export async function getBlogs(): Promise<Blog[]> {
-  const res = await fetch(`${API_URL}/blogs`, { cache: "no-store" });
+  const res = await request(`/blogs`);
   return res.json();
 }
 
 export async function getBlog(id: string | number): Promise<Blog> {
-  const res = await fetch(`${API_URL}/blogs/${id}`, { cache: "no-store" });
+  const res = await request(`/blogs/${id}`);
   return res.json();
 }
 
 export async function createBlog(data: FormData): Promise<Blog> {
-  const res = await fetch(`${API_URL}/blogs`, {
+  const res = await request(`/blogs`, {
     method: "POST",
     body: data,
   });
@@ -98,7 +200,7 @@ export async function createBlog(data: FormData): Promise<Blog> {
 }
 
 export async function updateBlog(id: string | number, data: FormData): Promise<Blog> {
-  const res = await fetch(`${API_URL}/blogs/${id}`, {
+  const res = await request(`/blogs/${id}`, {
     method: "PUT",
     body: data,
   });
@@ -106,24 +208,24 @@ export async function updateBlog(id: string | number, data: FormData): Promise<B
 }
 
 export async function deleteBlog(id: string | number): Promise<void> {
-  await fetch(`${API_URL}/blogs/${id}`, {
+  await request(`/blogs/${id}`, {
     method: "DELETE",
   });
 }
 
 // Languages
 export async function getLanguages(): Promise<Language[]> {
-  const res = await fetch(`${API_URL}/languages`, { cache: "no-store" });
+  const res = await request(`/languages`);
   return res.json();
 }
 
 export async function getLanguage(id: string): Promise<Language> {
-  const res = await fetch(`${API_URL}/languages/${id}`, { cache: "no-store" });
+  const res = await request(`/languages/${id}`);
   return res.json();
 }
 
 export async function createLanguage(data: FormData): Promise<Language> {
-  const res = await fetch(`${API_URL}/languages`, {
+  const res = await request(`/languages`, {
     method: "POST",
     body: data,
   });
@@ -131,7 +233,7 @@ export async function createLanguage(data: FormData): Promise<Language> {
 }
 
 export async function updateLanguage(id: string, data: FormData): Promise<Language> {
-  const res = await fetch(`${API_URL}/languages/${id}`, {
+  const res = await request(`/languages/${id}`, {
     method: "PUT",
     body: data,
   });
@@ -139,24 +241,24 @@ export async function updateLanguage(id: string, data: FormData): Promise<Langua
 }
 
 export async function deleteLanguage(id: string): Promise<void> {
-  await fetch(`${API_URL}/languages/${id}`, {
+  await request(`/languages/${id}`, {
     method: "DELETE",
   });
 }
 
 // Projects
 export async function getProjects(): Promise<Project[]> {
-  const res = await fetch(`${API_URL}/projects`, { cache: "no-store" });
+  const res = await request(`/projects`);
   return res.json();
 }
 
 export async function getProject(id: string): Promise<Project> {
-  const res = await fetch(`${API_URL}/projects/${id}`, { cache: "no-store" });
+  const res = await request(`/projects/${id}`);
   return res.json();
 }
 
 export async function createProject(data: FormData): Promise<Project> {
-  const res = await fetch(`${API_URL}/projects`, {
+  const res = await request(`/projects`, {
     method: "POST",
     body: data,
   });
@@ -164,7 +266,7 @@ export async function createProject(data: FormData): Promise<Project> {
 }
 
 export async function updateProject(id: string, data: FormData): Promise<Project> {
-  const res = await fetch(`${API_URL}/projects/${id}`, {
+  const res = await request(`/projects/${id}`, {
     method: "PUT",
     body: data,
   });
@@ -172,24 +274,44 @@ export async function updateProject(id: string, data: FormData): Promise<Project
 }
 
 export async function deleteProject(id: string): Promise<void> {
-  await fetch(`${API_URL}/projects/${id}`, {
+  await request(`/projects/${id}`, {
     method: "DELETE",
   });
 }
 
 // Categories
+
+// getCategories lädt alle Kategorien als flache Liste, die Hierarchie steckt
+// in parent_id. Ohne ?flat=true liefert die API nur die Wurzeln als Baum.
 export async function getCategories(): Promise<Category[]> {
-  const res = await fetch(`${API_URL}/categories`, { cache: "no-store" });
+  const res = await request(`/categories?flat=true&sort=name&limit=100`);
   return res.json();
 }
 
+// sortCategoryTree ordnet Unterkategorien direkt unter ihrer Elternkategorie
+// an und liefert dazu die Tiefe für die Einrückung.
+export function sortCategoryTree(categories: Category[]): { category: Category; depth: number }[] {
+  const ids = new Set(categories.map((category) => category.id));
+  const result: { category: Category; depth: number }[] = [];
+  const visit = (parentId: string | null, depth: number) => {
+    categories
+      .filter((category) => (category.parent_id && ids.has(category.parent_id) ? category.parent_id : null) === parentId)
+      .forEach((category) => {
+        result.push({ category, depth });
+        visit(category.id, depth + 1);
+      });
+  };
+  visit(null, 0);
+  return result;
+}
+
 export async function getCategory(id: string): Promise<Category> {
-  const res = await fetch(`${API_URL}/categories/${id}`, { cache: "no-store" });
+  const res = await request(`/categories/${id}`);
   return res.json();
 }
 
 export async function createCategory(data: FormData): Promise<Category> {
-  const res = await fetch(`${API_URL}/categories`, {
+  const res = await request(`/categories`, {
     method: "POST",
     body: data,
   });
@@ -197,7 +319,7 @@ export async function createCategory(data: FormData): Promise<Category> {
 }
 
 export async function updateCategory(id: string, data: FormData): Promise<Category> {
-  const res = await fetch(`${API_URL}/categories/${id}`, {
+  const res = await request(`/categories/${id}`, {
     method: "PUT",
     body: data,
   });
@@ -205,7 +327,7 @@ export async function updateCategory(id: string, data: FormData): Promise<Catego
 }
 
 export async function deleteCategory(id: string): Promise<void> {
-  await fetch(`${API_URL}/categories/${id}`, {
+  await request(`/categories/${id}`, {
     method: "DELETE",
   });
 }
@@ -0,0 +1,30 @@
+// Session der API (Antwort von /auth/login bzw. /auth/refresh). Die Admin
+// Oberfläche läuft komplett im Browser, die Tokens liegen im localStorage.
+export interface Session {
+  access_token: string;
+  refresh_token: string;
+  token_type: string;
+  expires_at: string;
+  refresh_expires_at: string;
+}
+
+const STORAGE_KEY = "portfolio-admin-session";
+
+export function getSession(): Session | null {
+  if (typeof window === "undefined") return null;
+  const value = window.localStorage.getItem(STORAGE_KEY);
+  if (!value) return null;
+  try {
+    return JSON.parse(value) as Session;
+  } catch {
+    return null;
+  }
+}
+
+export function setSession(session: Session) {
+  window.localStorage.setItem(STORAGE_KEY, JSON.stringify(session));
+}
+
+export function clearSession() {
+  window.localStorage.removeItem(STORAGE_KEY);
+}
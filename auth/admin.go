@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"os"
+)
+
+// CheckAdminCredentials prüft Benutzername und Passwort gegen
+// ADMIN_USERNAME und ADMIN_PASSWORD. Ohne gesetztes Passwort ist der
+// Admin-Login deaktiviert.
+func CheckAdminCredentials(username, password string) bool {
+	adminUser := os.Getenv("ADMIN_USERNAME")
+	if adminUser == "" {
+		adminUser = "admin"
+	}
+	adminPassword := os.Getenv("ADMIN_PASSWORD")
+	if adminPassword == "" {
+		return false
+	}
+
+	userOK := subtle.ConstantTimeCompare([]byte(username), []byte(adminUser)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(adminPassword)) == 1
+	return userOK && passwordOK
+}
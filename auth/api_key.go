@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+)
+
+// APIKeyPrefix kennzeichnet API Keys, damit sie im Authorization Header
+// von Session Tokens unterschieden werden können.
+const APIKeyPrefix = "pk_"
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// CreateAPIKey legt einen neuen API Key an. Der Klartext-Key wird nur hier
+// zurückgegeben, gespeichert wird lediglich der Hash.
+func CreateAPIKey(name, scope string, createdByID *string) (*models.APIKey, string, error) {
+	token, err := NewToken()
+	if err != nil {
+		return nil, "", err
+	}
+	key := APIKeyPrefix + token
+
+	apiKey := models.APIKey{
+		Name:        name,
+		Prefix:      key[:len(APIKeyPrefix)+8],
+		KeyHash:     HashToken(key),
+		Scope:       scope,
+		CreatedByID: createdByID,
+	}
+	if err := database.DB.Create(&apiKey).Error; err != nil {
+		return nil, "", err
+	}
+	return &apiKey, key, nil
+}
+
+// ValidateAPIKey sucht einen nicht widerrufenen API Key und aktualisiert
+// den Zeitpunkt der letzten Nutzung.
+func ValidateAPIKey(key string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	if err := database.DB.Where("key_hash = ? AND revoked_at IS NULL", HashToken(key)).First(&apiKey).Error; err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+	apiKey.LastUsedAt = &now
+	database.DB.Model(&apiKey).UpdateColumn("last_used_at", now)
+
+	return &apiKey, nil
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// CreateSession legt eine neue Session an und gibt die Klartext-Tokens zurück.
+// Die Tokens selbst werden nur gehasht gespeichert. userID ist nil für den
+// Admin aus den Umgebungsvariablen.
+func CreateSession(subject string, userID *string) (*TokenPair, error) {
+	accessToken, err := NewToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := NewToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		TokenHash:        HashToken(accessToken),
+		RefreshHash:      HashToken(refreshToken),
+		Subject:          subject,
+		UserID:           userID,
+		ExpiresAt:        now.Add(TokenTTL()),
+		RefreshExpiresAt: now.Add(RefreshTTL()),
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        session.ExpiresAt,
+		RefreshExpiresAt: session.RefreshExpiresAt,
+	}, nil
+}
+
+// ValidateToken sucht die Session zu einem Access Token.
+func ValidateToken(token string) (*models.Session, error) {
+	var session models.Session
+	if err := database.DB.Where("token_hash = ?", HashToken(token)).First(&session).Error; err != nil {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	return &session, nil
+}
+
+// RefreshSession tauscht einen Refresh Token gegen ein neues Token-Paar.
+// Die alte Session wird dabei gelöscht (Rotation).
+func RefreshSession(refreshToken string) (*TokenPair, error) {
+	var session models.Session
+	if err := database.DB.Where("refresh_hash = ?", HashToken(refreshToken)).First(&session).Error; err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := database.DB.Delete(&session).Error; err != nil {
+		return nil, err
+	}
+	if time.Now().After(session.RefreshExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	return CreateSession(session.Subject, session.UserID)
+}
+
+// RevokeSession löscht eine Session anhand ihrer ID.
+func RevokeSession(sessionID string) error {
+	return database.DB.Delete(&models.Session{}, "id = ?", sessionID).Error
+}
+
+// RevokeUserSessions löscht alle Sessions eines Users außer der angegebenen,
+// bei Bedarf innerhalb einer Transaktion.
+func RevokeUserSessions(db *gorm.DB, userID, exceptSessionID string) error {
+	return db.Where("user_id = ? AND id <> ?", userID, exceptSessionID).Delete(&models.Session{}).Error
+}
+
+// PruneSessions löscht alle Sessions, deren Refresh Token abgelaufen ist.
+func PruneSessions() error {
+	return database.DB.Where("refresh_expires_at < ?", time.Now()).Delete(&models.Session{}).Error
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"time"
+)
+
+const (
+	defaultTokenTTL   = time.Hour
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// NewToken erzeugt einen zufälligen, opaken Token (64 Hex-Zeichen).
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken liefert den SHA-256 Hash, der in der Datenbank gespeichert wird.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TokenTTL() time.Duration {
+	return durationEnv("AUTH_TOKEN_TTL", defaultTokenTTL)
+}
+
+func RefreshTTL() time.Duration {
+	return durationEnv("AUTH_REFRESH_TTL", defaultRefreshTTL)
+}
+
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
@@ -3,8 +3,11 @@ package database
 import (
 	"database/sql"
 	"os"
+	"strconv"
 
+	"PortfolioAPI/markdown"
 	"PortfolioAPI/models"
+	"PortfolioAPI/slug"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/mysql"
@@ -51,10 +54,139 @@ func Connect() {
 		panic("Failed to connect to database: " + err.Error())
 	}
 
-	err = database.AutoMigrate(&models.User{}, &models.Blog{}, &models.Language{}, &models.Project{}, &models.Category{})
+	// Vor AutoMigrate, sonst scheitert der eindeutige Index an leeren Slugs
+	if err := prepareCategorySlugs(database); err != nil {
+		panic("Failed to migrate category slugs: " + err.Error())
+	}
+
+	err = database.AutoMigrate(&models.User{}, &models.Blog{}, &models.Language{}, &models.Project{}, &models.Category{}, &models.Session{}, &models.APIKey{}, &models.Media{}, &models.BlogImage{}, &models.BlogRevision{}, &models.BlogSlug{}, &models.Comment{}, &models.Tag{}, &models.Series{})
 	if err != nil {
 		panic("Failed to migrate database: " + err.Error())
 	}
 
+	if err := backfillBlogOutlines(database); err != nil {
+		panic("Failed to backfill blog outlines: " + err.Error())
+	}
+
+	if err := createFulltextIndexes(database); err != nil {
+		panic("Failed to create fulltext indexes: " + err.Error())
+	}
+
 	DB = database
 }
+
+// backfillBlogOutlines berechnet Wortzahl, Lesezeit und Inhaltsverzeichnis
+// für Blogs aus der Zeit vor diesen Spalten, wie handlers.applyBlogOutline
+// beim Speichern. updated_at bleibt unverändert.
+func backfillBlogOutlines(db *gorm.DB) error {
+	var blogs []models.Blog
+	return db.Select("id, content").Where("word_count = 0 AND content <> ''").
+		FindInBatches(&blogs, 100, func(tx *gorm.DB, batch int) error {
+			for _, blog := range blogs {
+				outline, err := markdown.Analyze(blog.Content)
+				if err != nil {
+					return err
+				}
+				toc := make([]models.BlogHeading, len(outline.Headings))
+				for i, heading := range outline.Headings {
+					toc[i] = models.BlogHeading{Level: heading.Level, Text: heading.Text, Anchor: heading.Anchor}
+				}
+				err = db.Model(&models.Blog{ID: blog.ID}).Select("word_count", "reading_time", "toc").
+					UpdateColumns(models.Blog{WordCount: outline.WordCount, ReadingTime: outline.ReadingTime, TOC: toc}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// Volltext-Indizes für die Suche. Die Spalten müssen mit dem MATCH() in
+// handlers/search.go übereinstimmen.
+var fulltextIndexes = []struct {
+	Table   string
+	Name    string
+	Columns string
+}{
+	{"blogs", "idx_blogs_fulltext", "title, excerpt, content"},
+	{"projects", "idx_projects_fulltext", "title, description"},
+}
+
+func createFulltextIndexes(db *gorm.DB) error {
+	for _, index := range fulltextIndexes {
+		if db.Migrator().HasIndex(index.Table, index.Name) {
+			continue
+		}
+		if err := db.Exec("CREATE FULLTEXT INDEX " + index.Name + " ON " + index.Table + " (" + index.Columns + ")").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareCategorySlugs bereitet bestehende Kategorien auf den eindeutigen
+// Slug-Index vor: Spalte anlegen, Slugs auffüllen und den alten, nicht
+// eindeutigen Index entfernen.
+func prepareCategorySlugs(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.Category{}) {
+		return nil
+	}
+	if !migrator.HasColumn(&models.Category{}, "Slug") {
+		if err := migrator.AddColumn(&models.Category{}, "Slug"); err != nil {
+			return err
+		}
+	}
+	if err := backfillCategorySlugs(db); err != nil {
+		return err
+	}
+	if migrator.HasIndex(&models.Category{}, "idx_categories_slug") {
+		return migrator.DropIndex(&models.Category{}, "idx_categories_slug")
+	}
+	return nil
+}
+
+// backfillCategorySlugs gibt Kategorien aus der Zeit vor den Slugs einen
+// eindeutigen Slug aus ihrem Namen. Bei doppelten Slugs behält die älteste
+// Kategorie ihren, die anderen bekommen einen neuen.
+func backfillCategorySlugs(db *gorm.DB) error {
+	var categories []models.Category
+	if err := db.Where("slug = '' OR slug IS NULL").Find(&categories).Error; err != nil {
+		return err
+	}
+
+	var duplicates []models.Category
+	duplicated := db.Model(&models.Category{}).Select("slug").Where("slug <> ''").Group("slug").Having("COUNT(*) > 1")
+	if err := db.Where("slug IN (?)", duplicated).Order("created_at ASC, id ASC").Find(&duplicates).Error; err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, category := range duplicates {
+		if seen[category.Slug] {
+			categories = append(categories, category)
+		}
+		seen[category.Slug] = true
+	}
+
+	for _, category := range categories {
+		base := slug.Make(category.Name)
+		if base == "" {
+			base = "category"
+		}
+		s := base
+		for i := 2; ; i++ {
+			var count int64
+			if err := db.Model(&models.Category{}).Where("slug = ?", s).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				break
+			}
+			s = base + "-" + strconv.Itoa(i)
+		}
+		if err := db.Model(&category).Update("slug", s).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
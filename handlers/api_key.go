@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"PortfolioAPI/auth"
+	"PortfolioAPI/database"
+	"PortfolioAPI/middleware"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetAPIKeys(c *gin.Context) {
+	apiKeys := []models.APIKey{}
+	if err := database.DB.Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		problem.Database(c, err, "Failed to load API keys")
+		return
+	}
+	c.JSON(http.StatusOK, apiKeys)
+}
+
+func CreateAPIKey(c *gin.Context) {
+	name := c.PostForm("name")
+	scope := c.PostForm("scope")
+
+	if name == "" {
+		problem.Field(c, "name", problem.FieldRequired, "Name is required")
+		return
+	}
+	if scope == "" {
+		scope = models.ScopeRead
+	}
+	if !models.IsValidScope(scope) {
+		problem.Field(c, "scope", problem.FieldInvalid, "Invalid scope")
+		return
+	}
+
+	var createdByID *string
+	if user := middleware.CurrentUser(c); user != nil {
+		createdByID = &user.ID
+	}
+
+	apiKey, key, err := auth.CreateAPIKey(name, scope, createdByID)
+	if err != nil {
+		problem.Database(c, err, "Failed to create API key")
+		return
+	}
+
+	// Der Key wird nur bei der Erstellung einmalig ausgegeben
+	c.JSON(http.StatusCreated, gin.H{"api_key": apiKey, "key": key})
+}
+
+func RevokeAPIKey(c *gin.Context) {
+	var apiKey models.APIKey
+	if err := database.DB.First(&apiKey, "id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "API key not found")
+		return
+	}
+
+	if apiKey.RevokedAt == nil {
+		now := time.Now()
+		apiKey.RevokedAt = &now
+		if err := database.DB.Save(&apiKey).Error; err != nil {
+			problem.Database(c, err, "Failed to revoke API key")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"PortfolioAPI/auth"
+	"PortfolioAPI/database"
+	"PortfolioAPI/middleware"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func Login(c *gin.Context) {
+	email := c.PostForm("email")
+	username := c.PostForm("username")
+	password := c.PostForm("password")
+
+	if email == "" && username == "" {
+		problem.Field(c, "email", problem.FieldRequired, "Email and password are required")
+		return
+	}
+	if password == "" {
+		problem.Field(c, "password", problem.FieldRequired, "Email and password are required")
+		return
+	}
+
+	// Abgelaufene Sessions bei der Gelegenheit aufräumen
+	auth.PruneSessions()
+
+	// Admin aus den Umgebungsvariablen
+	if username != "" {
+		if !auth.CheckAdminCredentials(username, password) {
+			problem.AbortCode(c, http.StatusUnauthorized, problem.CodeInvalidCredential, "Invalid credentials")
+			return
+		}
+		tokens, err := auth.CreateSession(username, nil)
+		if err != nil {
+			problem.Database(c, err, "Failed to create session")
+			return
+		}
+		c.JSON(http.StatusOK, tokens)
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "email = ?", email).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			problem.Database(c, err, "Failed to load user")
+			return
+		}
+		auth.CheckPassword("", password)
+		problem.AbortCode(c, http.StatusUnauthorized, problem.CodeInvalidCredential, "Invalid credentials")
+		return
+	}
+	if !auth.CheckPassword(user.PasswordHash, password) {
+		problem.AbortCode(c, http.StatusUnauthorized, problem.CodeInvalidCredential, "Invalid credentials")
+		return
+	}
+
+	tokens, err := auth.CreateSession(email, &user.ID)
+	if err != nil {
+		problem.Database(c, err, "Failed to create session")
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func Logout(c *gin.Context) {
+	session := middleware.CurrentSession(c)
+	if session == nil {
+		problem.Abort(c, http.StatusBadRequest, "Only sessions can be logged out")
+		return
+	}
+	if err := auth.RevokeSession(session.ID); err != nil {
+		problem.Database(c, err, "Failed to logout")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+func ChangePassword(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	if user == nil {
+		problem.Abort(c, http.StatusBadRequest, "Password can only be changed for user accounts")
+		return
+	}
+
+	currentPassword := c.PostForm("current_password")
+	newPassword := c.PostForm("new_password")
+
+	if newPassword == "" {
+		problem.Field(c, "new_password", problem.FieldRequired, "New password is required")
+		return
+	}
+	if !auth.CheckPassword(user.PasswordHash, currentPassword) {
+		problem.AbortCode(c, http.StatusUnauthorized, problem.CodeInvalidCredential, "Current password is incorrect")
+		return
+	}
+
+	hash, err := auth.HashPassword(newPassword)
+	if err == auth.ErrPasswordTooShort {
+		problem.Field(c, "new_password", problem.FieldInvalid, err.Error())
+		return
+	}
+	if err != nil {
+		problem.Internal(c, problem.CodeInternal, err, "Failed to hash password")
+		return
+	}
+
+	// Transaktion: neues Passwort nur zusammen mit dem Abmelden aller
+	// anderen Sessions
+	tx := database.DB.Begin()
+
+	if err := tx.Model(user).Update("password_hash", hash).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to update password")
+		return
+	}
+
+	exceptSessionID := ""
+	if session := middleware.CurrentSession(c); session != nil {
+		exceptSessionID = session.ID
+	}
+	if err := auth.RevokeUserSessions(tx, user.ID, exceptSessionID); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to revoke other sessions")
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to update password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed"})
+}
+
+func RefreshToken(c *gin.Context) {
+	refreshToken := c.PostForm("refresh_token")
+	if refreshToken == "" {
+		problem.Field(c, "refresh_token", problem.FieldRequired, "Refresh token is required")
+		return
+	}
+
+	tokens, err := auth.RefreshSession(refreshToken)
+	if err == auth.ErrInvalidToken {
+		problem.Abort(c, http.StatusUnauthorized, "Invalid or expired refresh token")
+		return
+	}
+	if err != nil {
+		problem.Database(c, err, "Failed to refresh session")
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// withCurrentAuthor trägt einen eingeloggten Author immer selbst in die
+// Author-Liste ein, damit er seine Einträge danach noch bearbeiten darf.
+func withCurrentAuthor(c *gin.Context, authorIDsStr string) string {
+	user := middleware.CurrentUser(c)
+	if user == nil || user.Role != models.RoleAuthor {
+		return authorIDsStr
+	}
+	if authorIDsStr == "" {
+		return user.ID
+	}
+	for _, id := range strings.Split(authorIDsStr, ",") {
+		if id == user.ID {
+			return authorIDsStr
+		}
+	}
+	return authorIDsStr + "," + user.ID
+}
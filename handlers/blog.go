@@ -1,20 +1,27 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"PortfolioAPI/database"
+	"PortfolioAPI/markdown"
+	"PortfolioAPI/middleware"
 	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/storage"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func addBlogCDNPrefix(blog *models.Blog) {
+	if blog.ImageMedia != nil {
+		blog.Images = imageSetFor(blog.ImageMedia)
+	}
 	if blog.Image != "" && !strings.HasPrefix(blog.Image, "http") {
 		blog.Image = getCDNURL() + blog.Image
 	}
@@ -25,41 +32,272 @@ func addBlogCDNPrefix(blog *models.Blog) {
 	}
 }
 
+// Gerendertes HTML pro Blog, gültig solange sich UpdatedAt nicht ändert
+var blogHTMLCache = markdown.NewCache()
+
+// renderBlogContent füllt ContentHTML, wenn ?format=html angefragt wurde.
+func renderBlogContent(c *gin.Context, blog *models.Blog) error {
+	if c.Query("format") != "html" {
+		return nil
+	}
+	rendered, err := blogHTMLCache.Render(strconv.Itoa(int(blog.ID)), blog.UpdatedAt, blog.Content)
+	if err != nil {
+		return err
+	}
+	blog.ContentHTML = rendered
+	return nil
+}
+
+// applyBlogOutline berechnet Wortanzahl, Lesezeit und Inhaltsverzeichnis
+// aus dem Content.
+func applyBlogOutline(blog *models.Blog) error {
+	outline, err := markdown.Analyze(blog.Content)
+	if err != nil {
+		return err
+	}
+	blog.WordCount = outline.WordCount
+	blog.ReadingTime = outline.ReadingTime
+	blog.TOC = make([]models.BlogHeading, len(outline.Headings))
+	for i, heading := range outline.Headings {
+		blog.TOC[i] = models.BlogHeading{Level: heading.Level, Text: heading.Text, Anchor: heading.Anchor}
+	}
+	return nil
+}
+
+// publishedBlogs beschränkt eine Abfrage auf öffentlich sichtbare Blogs.
+// Fällige geplante Blogs zählen schon als veröffentlicht, bevor der
+// Scheduler ihren Status umstellt.
+func publishedBlogs(db *gorm.DB) *gorm.DB {
+	return db.Where("blogs.status = ? OR (blogs.status = ? AND blogs.published_at <= ?)",
+		models.BlogStatusPublished, models.BlogStatusScheduled, time.Now())
+}
+
+// visibleBlogs zeigt Admins und Editoren alle Blogs. Authoren sehen
+// zusätzlich zu den veröffentlichten ihre eigenen Entwürfe, alle anderen
+// (auch API Keys mit Scope read) nur veröffentlichte.
+func visibleBlogs(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		role := middleware.CurrentRole(c)
+		if role == models.RoleAdmin || role == models.RoleEditor {
+			return db
+		}
+		if user := middleware.CurrentUser(c); user != nil && role == models.RoleAuthor {
+			own := database.DB.Table("blog_authors").Select("blog_id").Where("user_id = ?", user.ID)
+			return db.Where("blogs.status = ? OR (blogs.status = ? AND blogs.published_at <= ?) OR blogs.id IN (?)",
+				models.BlogStatusPublished, models.BlogStatusScheduled, time.Now(), own)
+		}
+		return publishedBlogs(db)
+	}
+}
+
+// applyBlogStatus setzt Status und Veröffentlichungszeitpunkt. Geplante Blogs
+// brauchen einen Zeitpunkt in der Zukunft, veröffentlichte bekommen "jetzt",
+// falls noch keiner gesetzt ist.
+func applyBlogStatus(blog *models.Blog, status, publishedAtStr string) *problem.FieldError {
+	if publishedAtStr != "" {
+		t, err := time.Parse(time.RFC3339, publishedAtStr)
+		if err != nil {
+			t, err = time.ParseInLocation("2006-01-02T15:04", publishedAtStr, time.Local)
+		}
+		if err != nil {
+			return &problem.FieldError{Field: "published_at", Code: problem.FieldInvalid, Message: "Invalid published_at, expected RFC3339"}
+		}
+		blog.PublishedAt = &t
+	}
+
+	if status != "" {
+		if !models.IsValidBlogStatus(status) {
+			return &problem.FieldError{Field: "status", Code: problem.FieldInvalid, Message: "Invalid status"}
+		}
+		blog.Status = status
+	}
+
+	switch blog.Status {
+	case models.BlogStatusScheduled:
+		if blog.PublishedAt == nil || !blog.PublishedAt.After(time.Now()) {
+			return &problem.FieldError{Field: "published_at", Code: problem.FieldInvalid, Message: "Scheduled blogs need a published_at in the future"}
+		}
+	case models.BlogStatusPublished:
+		if blog.PublishedAt == nil {
+			now := time.Now()
+			blog.PublishedAt = &now
+		}
+	}
+	return nil
+}
+
+// blogListSpec legt Filter und Sortierung für GET /blogs fest.
+var blogListSpec = listSpec{
+	Table: "blogs",
+	Sorts: []string{"created_at", "updated_at", "published_at", "title", "pinned", "word_count", "reading_time"},
+	Filters: map[string]filterField{
+		"title":        {Column: "title"},
+		"slug":         {Column: "slug"},
+		"status":       {Column: "status"},
+		"pinned":       {Column: "pinned", Type: filterBool},
+		"series_id":    {Column: "series_id"},
+		"word_count":   {Column: "word_count", Type: filterInt},
+		"reading_time": {Column: "reading_time", Type: filterInt},
+		"published_at": {Column: "published_at", Type: filterTime},
+		"created_at":   {Column: "created_at", Type: filterTime},
+		"updated_at":   {Column: "updated_at", Type: filterTime},
+		"author_id":    {Column: "user_id", Through: "blog_authors", Key: "blog_id"},
+		"category_id":  {Column: "category_id", Through: "blog_categories", Key: "blog_id"},
+	},
+	DefaultSort: []sortKey{{"pinned", true}, {"created_at", true}},
+}
+
+// blogFieldSpec legt ?fields= und ?include= für Blogs fest.
+var blogFieldSpec = fieldSpec{
+	Table: "blogs",
+	Fields: []string{"id", "title", "slug", "excerpt", "content", "word_count", "reading_time", "toc",
+		"image", "image_media_id", "pinned", "status", "published_at", "series_id", "series_position",
+		"created_at", "updated_at"},
+	Computed: map[string]computedField{
+		"images":       {Columns: []string{"image_media_id"}, Preload: "ImageMedia"},
+		"content_html": {Columns: []string{"content", "updated_at"}, DetailOnly: true},
+		"series":       {Columns: []string{"series_id"}, DetailOnly: true},
+	},
+	Includes: map[string]includeFunc{
+		"authors":    preloadInclude("Authors"),
+		"categories": preloadInclude("Categories"),
+		"tags":       preloadInclude("Tags"),
+	},
+	DefaultIncludes: []string{"authors", "categories", "tags"},
+}
+
 func GetBlogs(c *gin.Context) {
 	blogs := []models.Blog{}
 	categoryID := c.Query("category_id")
 
-	query := database.DB.Preload("Authors").Preload("Categories")
+	query := database.DB.Scopes(visibleBlogs(c), taggedWith(c, "blogs", "blog_tags", "blog_id"))
+	if status := c.Query("status"); status != "" && middleware.IsAuthenticated(c) {
+		query = query.Where("blogs.status = ?", status)
+	}
 	if categoryID != "" {
 		query = query.Joins("JOIN blog_categories ON blog_categories.blog_id = blogs.id").
 			Where("blog_categories.category_id = ?", categoryID)
 	}
+	// ?category=<slug> schließt alle Unterkategorien mit ein
+	if categorySlug := c.Query("category"); categorySlug != "" {
+		categories := []models.Category{}
+		if err := database.DB.Find(&categories).Error; err != nil {
+			problem.Database(c, err, "Failed to load categories")
+			return
+		}
+		var categoryIDs []string
+		for _, category := range categories {
+			if category.Slug == categorySlug {
+				categoryIDs = categoryDescendantIDs(categories, category.ID)
+				break
+			}
+		}
+		// Unbekannter Slug: leere Liste, aber mit normaler Prüfung und Headern
+		if categoryIDs == nil {
+			query = query.Where("1 = 0")
+		} else {
+			query = query.Where("blogs.id IN (?)", database.DB.Table("blog_categories").
+				Select("blog_id").Where("category_id IN ?", categoryIDs))
+		}
+	}
 
-	query.Order("pinned DESC, created_at DESC").Find(&blogs)
+	query, keys, err := listQuery(c, blogListSpec, query)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	query, keep, err := selectFields(c, blogFieldSpec.forList(), query, keys)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if err := paginate(c, query, keys, &blogs); err != nil {
+		respondPaginationError(c, err)
+		return
+	}
 	for i := range blogs {
 		addBlogCDNPrefix(&blogs[i])
 	}
-	c.JSON(http.StatusOK, blogs)
+	result, err := sparseJSON(blogs, keep)
+	if err != nil {
+		problem.Internal(c, problem.CodeInternal, err, "Failed to encode response")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// respondBlog ergänzt Serie, HTML und CDN-URLs und kürzt die Antwort auf
+// die Felder aus ?fields=.
+func respondBlog(c *gin.Context, blog *models.Blog, keep []string) {
+	if err := attachSeries(c, blog); err != nil {
+		problem.Database(c, err, "Failed to load series")
+		return
+	}
+	if err := renderBlogContent(c, blog); err != nil {
+		problem.Internal(c, problem.CodeInternal, err, "Failed to render content")
+		return
+	}
+	addBlogCDNPrefix(blog)
+	result, err := sparseJSON(blog, keep)
+	if err != nil {
+		problem.Internal(c, problem.CodeInternal, err, "Failed to encode blog")
+		return
+	}
+	c.JSON(http.StatusOK, result)
 }
 
 func GetBlog(c *gin.Context) {
 	var blog models.Blog
-	if err := database.DB.Preload("Authors").Preload("Categories").First(&blog, c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+	query, keep, err := selectFields(c, blogFieldSpec, database.DB.Scopes(visibleBlogs(c)), nil)
+	if err != nil {
+		respondPaginationError(c, err)
 		return
 	}
-	addBlogCDNPrefix(&blog)
-	c.JSON(http.StatusOK, blog)
+	if err := query.First(&blog, "blogs.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Blog not found")
+		return
+	}
+	respondBlog(c, &blog, keep)
 }
 
 func GetBlogBySlug(c *gin.Context) {
 	var blog models.Blog
-	if err := database.DB.Preload("Authors").Preload("Categories").Where("slug = ?", c.Param("slug")).First(&blog).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+	query, keep, err := selectFields(c, blogFieldSpec, database.DB.Scopes(visibleBlogs(c)), nil)
+	if err != nil {
+		respondPaginationError(c, err)
 		return
 	}
-	addBlogCDNPrefix(&blog)
-	c.JSON(http.StatusOK, blog)
+	if err := query.Where("blogs.slug = ?", c.Param("slug")).First(&blog).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			problem.Database(c, err, "Failed to load blog")
+			return
+		}
+		// Alter Slug? Dann auf den aktuellen weiterleiten
+		var old models.BlogSlug
+		if database.DB.Where("slug = ?", c.Param("slug")).First(&old).Error == nil &&
+			database.DB.Scopes(visibleBlogs(c)).First(&blog, old.BlogID).Error == nil {
+			location := "/blogs/slug/" + blog.Slug
+			if c.Request.URL.RawQuery != "" {
+				location += "?" + c.Request.URL.RawQuery
+			}
+			c.Header("Location", location)
+			c.JSON(http.StatusMovedPermanently, gin.H{"message": "Blog has moved", "slug": blog.Slug, "location": location})
+			return
+		}
+		problem.Abort(c, http.StatusNotFound, "Blog not found")
+		return
+	}
+	respondBlog(c, &blog, keep)
+}
+
+// GetHighlightCSS liefert das Stylesheet für Codeblöcke in content_html.
+func GetHighlightCSS(c *gin.Context) {
+	css, err := markdown.HighlightCSS()
+	if err != nil {
+		problem.Internal(c, problem.CodeInternal, err, "Failed to generate stylesheet")
+		return
+	}
+	c.Data(http.StatusOK, "text/css; charset=utf-8", []byte(css))
 }
 
 func CreateBlog(c *gin.Context) {
@@ -69,22 +307,56 @@ func CreateBlog(c *gin.Context) {
 	content := c.PostForm("content")
 	imageURL := c.PostForm("image")
 	pinnedStr := c.PostForm("pinned")
-	authorIDsStr := c.PostForm("author_ids")
+	status := c.PostForm("status")
+	publishedAtStr := c.PostForm("published_at")
+	authorIDsStr := withCurrentAuthor(c, c.PostForm("author_ids"))
 	categoryIDsStr := c.PostForm("category_ids")
 
-	if title == "" || slug == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Title and slug are required"})
-		return
+	var missing []problem.FieldError
+	if title == "" {
+		missing = append(missing, problem.FieldError{Field: "title", Code: problem.FieldRequired, Message: "Title is required"})
 	}
 	if authorIDsStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Author IDs are required"})
+		missing = append(missing, problem.FieldError{Field: "author_ids", Code: problem.FieldRequired, Message: "Author IDs are required"})
+	}
+	if len(missing) > 0 {
+		problem.Invalid(c, missing...)
+		return
+	}
+	tagNames, ok := formTags(c, c.PostForm("tags"))
+	if !ok {
+		return
+	}
+	// Ohne Slug wird einer aus dem Titel erzeugt
+	if slug == "" {
+		generated, err := uniqueBlogSlug(title)
+		if err != nil {
+			problem.Database(c, err, "Failed to generate slug")
+			return
+		}
+		slug = generated
+	} else {
+		normalized, ok := checkBlogSlug(c, slug, 0)
+		if !ok {
+			return
+		}
+		slug = normalized
+	}
+
+	// Bild hochgeladen oder aus der Mediathek gewählt?
+	image, ok := mediaFromForm(c, "image_file", "image_media_id", blogImageUpload)
+	if !ok {
 		return
 	}
 
 	authorIDs := strings.Split(authorIDsStr, ",")
 	var authors []models.User
-	if err := database.DB.Where("id IN ?", authorIDs).Find(&authors).Error; err != nil || len(authors) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Authors not found"})
+	if err := database.DB.Where("id IN ?", authorIDs).Find(&authors).Error; err != nil {
+		problem.Database(c, err, "Failed to load authors")
+		return
+	}
+	if len(authors) == 0 {
+		problem.Field(c, "author_ids", problem.FieldInvalid, "Authors not found")
 		return
 	}
 
@@ -96,50 +368,85 @@ func CreateBlog(c *gin.Context) {
 		Image:   imageURL,
 		Pinned:  pinnedStr == "true" || pinnedStr == "1",
 		Authors: authors,
+		Status:  models.BlogStatusPublished,
+	}
+	if fieldErr := applyBlogStatus(&blog, status, publishedAtStr); fieldErr != nil {
+		problem.Invalid(c, *fieldErr)
+		return
+	}
+	if err := applyBlogOutline(&blog); err != nil {
+		problem.Field(c, "content", problem.FieldInvalid, "Failed to parse content")
+		return
+	}
+
+	// Upload erst speichern, wenn alles andere gültig ist
+	media, ok := image.save(c)
+	if !ok {
+		return
+	}
+	defer image.cleanup(c)
+	if media != nil {
+		blog.Image = media.URL
+		blog.ImageMediaID = &media.ID
 	}
 
-	database.DB.Create(&blog)
+	// Transaktion: Blog und Verknüpfungen ganz oder gar nicht speichern
+	tx := database.DB.Begin()
+
+	if err := tx.Create(&blog).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to create blog")
+		return
+	}
 
 	// Categories hinzufügen
 	if categoryIDsStr != "" {
-		categoryIDs := strings.Split(categoryIDsStr, ",")
 		var categories []models.Category
-		if err := database.DB.Where("id IN ?", categoryIDs).Find(&categories).Error; err == nil {
-			database.DB.Model(&blog).Association("Categories").Replace(categories)
+		if err := tx.Where("id IN ?", strings.Split(categoryIDsStr, ",")).Find(&categories).Error; err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to load categories")
+			return
 		}
-	}
-
-	// Bild hochgeladen?
-	file, err := c.FormFile("image_file")
-	if err == nil {
-		blogDir := filepath.Join("public", "blogs", strconv.Itoa(int(blog.ID)))
-		if err := os.MkdirAll(blogDir, 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create blog directory"})
+		if err := tx.Model(&blog).Association("Categories").Replace(categories); err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to save categories")
 			return
 		}
+	}
 
-		ext := filepath.Ext(file.Filename)
-		filename := fmt.Sprintf("image%s", ext)
-		filePath := filepath.Join(blogDir, filename)
-
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
+	// Tags nach Namen zuordnen, neue werden angelegt
+	if len(tagNames) > 0 {
+		tags, err := tagsByName(tx, tagNames)
+		if err == nil {
+			err = tx.Model(&blog).Association("Tags").Replace(tags)
+		}
+		if err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to save tags")
 			return
 		}
+	}
 
-		blog.Image = fmt.Sprintf("/blogs/%d/%s", blog.ID, filename)
-		database.DB.Save(&blog)
+	if err := saveBlogRevision(tx, c, &blog, "Created"); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to save blog revision")
+		return
 	}
 
-	database.DB.Preload("Authors").Preload("Categories").First(&blog, blog.ID)
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to create blog")
+		return
+	}
+	image.keep()
+
 	addBlogCDNPrefix(&blog)
 	c.JSON(http.StatusCreated, blog)
 }
 
 func UpdateBlog(c *gin.Context) {
 	var blog models.Blog
-	if err := database.DB.First(&blog, c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+	if err := database.DB.First(&blog, "blogs.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Blog not found")
 		return
 	}
 
@@ -150,14 +457,21 @@ func UpdateBlog(c *gin.Context) {
 	content := c.PostForm("content")
 	imageURL := c.PostForm("image")
 	pinnedStr := c.PostForm("pinned")
+	status := c.PostForm("status")
+	publishedAtStr := c.PostForm("published_at")
 	authorIDsStr := c.PostForm("author_ids")
 	categoryIDsStr := c.PostForm("category_ids")
 
 	if title != "" {
 		blog.Title = title
 	}
+	oldSlug := blog.Slug
 	if slug != "" {
-		blog.Slug = slug
+		normalized, ok := checkBlogSlug(c, slug, blog.ID)
+		if !ok {
+			return
+		}
+		blog.Slug = normalized
 	}
 	if excerpt != "" {
 		blog.Excerpt = excerpt
@@ -167,64 +481,127 @@ func UpdateBlog(c *gin.Context) {
 	}
 	if imageURL != "" {
 		blog.Image = imageURL
+		blog.ImageMediaID = nil
 	}
 	if pinnedStr != "" {
 		blog.Pinned = pinnedStr == "true" || pinnedStr == "1"
 	}
-
-	// Bild hochgeladen?
-	file, err := c.FormFile("image_file")
-	if err == nil {
-		blogDir := filepath.Join("public", "blogs", strconv.Itoa(int(blog.ID)))
-		if err := os.MkdirAll(blogDir, 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create blog directory"})
+	if status != "" || publishedAtStr != "" {
+		if fieldErr := applyBlogStatus(&blog, status, publishedAtStr); fieldErr != nil {
+			problem.Invalid(c, *fieldErr)
 			return
 		}
+	}
+	tagsStr, hasTags := c.GetPostForm("tags")
+	tagNames, ok := formTags(c, tagsStr)
+	if !ok {
+		return
+	}
 
-		// Alte Bilder löschen
-		matches, _ := filepath.Glob(filepath.Join(blogDir, "image.*"))
-		for _, match := range matches {
-			os.Remove(match)
-		}
+	// Bild hochgeladen oder aus der Mediathek gewählt?
+	image, ok := mediaFromForm(c, "image_file", "image_media_id", blogImageUpload)
+	if !ok {
+		return
+	}
 
-		ext := filepath.Ext(file.Filename)
-		filename := fmt.Sprintf("image%s", ext)
-		filePath := filepath.Join(blogDir, filename)
+	if err := applyBlogOutline(&blog); err != nil {
+		problem.Field(c, "content", problem.FieldInvalid, "Failed to parse content")
+		return
+	}
 
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
-			return
-		}
+	// Upload erst speichern, wenn alles andere gültig ist
+	media, ok := image.save(c)
+	if !ok {
+		return
+	}
+	defer image.cleanup(c)
+	if media != nil {
+		blog.Image = media.URL
+		blog.ImageMediaID = &media.ID
+	}
+
+	// Transaktion: Blog und Verknüpfungen ganz oder gar nicht speichern
+	tx := database.DB.Begin()
 
-		blog.Image = fmt.Sprintf("/blogs/%d/%s", blog.ID, filename)
+	// Stand vor der Änderung sichern, falls es noch keine Revision gibt
+	if err := ensureBaseRevision(tx, blog.ID); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to save blog revision")
+		return
 	}
 
 	// Author IDs verarbeiten
 	if authorIDsStr != "" {
-		authorIDs := strings.Split(authorIDsStr, ",")
 		var authors []models.User
-		database.DB.Where("id IN ?", authorIDs).Find(&authors)
-		database.DB.Model(&blog).Association("Authors").Replace(authors)
+		if err := tx.Where("id IN ?", strings.Split(authorIDsStr, ",")).Find(&authors).Error; err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to load authors")
+			return
+		}
+		if err := tx.Model(&blog).Association("Authors").Replace(authors); err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to save authors")
+			return
+		}
 	}
 
 	// Category IDs verarbeiten
 	if categoryIDsStr != "" {
-		categoryIDs := strings.Split(categoryIDsStr, ",")
 		var categories []models.Category
-		database.DB.Where("id IN ?", categoryIDs).Find(&categories)
-		database.DB.Model(&blog).Association("Categories").Replace(categories)
+		if err := tx.Where("id IN ?", strings.Split(categoryIDsStr, ",")).Find(&categories).Error; err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to load categories")
+			return
+		}
+		if err := tx.Model(&blog).Association("Categories").Replace(categories); err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to save categories")
+			return
+		}
+	}
+
+	// Tags verarbeiten, ein leeres Feld entfernt alle Tags
+	if hasTags {
+		tags, err := tagsByName(tx, tagNames)
+		if err == nil {
+			err = tx.Model(&blog).Association("Tags").Replace(tags)
+		}
+		if err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to save tags")
+			return
+		}
+	}
+
+	if err := tx.Save(&blog).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to update blog")
+		return
+	}
+	if err := recordBlogSlugChange(tx, blog.ID, oldSlug, blog.Slug); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to save slug history")
+		return
+	}
+	if err := saveBlogRevision(tx, c, &blog, ""); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to save blog revision")
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to update blog")
+		return
 	}
+	image.keep()
 
-	database.DB.Save(&blog)
-	database.DB.Preload("Authors").Preload("Categories").First(&blog, blog.ID)
 	addBlogCDNPrefix(&blog)
 	c.JSON(http.StatusOK, blog)
 }
 
 func DeleteBlog(c *gin.Context) {
 	var blog models.Blog
-	if err := database.DB.First(&blog, c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+	if err := database.DB.First(&blog, "blogs.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Blog not found")
 		return
 	}
 
@@ -235,26 +612,60 @@ func DeleteBlog(c *gin.Context) {
 
 	if err := tx.Exec("DELETE FROM blog_authors WHERE blog_id = ?", blogID).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blog authors"})
+		problem.Database(c, err, "Failed to delete blog authors")
 		return
 	}
 
 	if err := tx.Exec("DELETE FROM blog_categories WHERE blog_id = ?", blogID).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blog categories"})
+		problem.Database(c, err, "Failed to delete blog categories")
+		return
+	}
+
+	if err := tx.Exec("DELETE FROM blog_tags WHERE blog_id = ?", blogID).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to delete blog tags")
+		return
+	}
+
+	if err := tx.Where("blog_id = ?", blogID).Delete(&models.BlogImage{}).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to delete blog images")
+		return
+	}
+
+	if err := tx.Where("blog_id = ?", blogID).Delete(&models.BlogRevision{}).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to delete blog revisions")
+		return
+	}
+
+	if err := tx.Where("blog_id = ?", blogID).Delete(&models.BlogSlug{}).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to delete blog slugs")
+		return
+	}
+
+	if err := tx.Where("blog_id = ?", blogID).Delete(&models.Comment{}).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to delete blog comments")
 		return
 	}
 
 	if err := tx.Delete(&blog).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blog"})
+		problem.Database(c, err, "Failed to delete blog")
 		return
 	}
 
-	tx.Commit()
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to delete blog")
+		return
+	}
+	blogHTMLCache.Forget(strconv.Itoa(int(blogID)))
 
-	// Blog-Bilder Ordner löschen
-	os.RemoveAll(filepath.Join("public", "blogs", strconv.Itoa(int(blogID))))
+	// Asset-Ordner des Blogs löschen (Inline-Bilder und alte Uploads)
+	storage.Store.DeletePrefix(c.Request.Context(), "blogs/"+strconv.Itoa(int(blogID)))
 
 	c.JSON(http.StatusOK, gin.H{"message": "Blog deleted"})
 }
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxBlogImagesPerUpload = 20
+
+// Inline-Bilder liegen im Asset-Ordner des Blogs und werden mit ihm gelöscht
+func blogImageKey(blogID uint, imageID, ext string) string {
+	return fmt.Sprintf("blogs/%d/content/%s%s", blogID, imageID, ext)
+}
+
+func GetBlogImages(c *gin.Context) {
+	var blog models.Blog
+	if err := database.DB.Scopes(visibleBlogs(c)).First(&blog, "blogs.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Blog not found")
+		return
+	}
+
+	images := []models.BlogImage{}
+	if err := database.DB.Where("blog_id = ?", blog.ID).Order("created_at ASC").Find(&images).Error; err != nil {
+		problem.Database(c, err, "Failed to load blog images")
+		return
+	}
+	for i := range images {
+		images[i].URL = withCDNPrefix(images[i].URL)
+	}
+	c.JSON(http.StatusOK, images)
+}
+
+func CreateBlogImages(c *gin.Context) {
+	var blog models.Blog
+	if err := database.DB.First(&blog, "blogs.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Blog not found")
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["images"]) == 0 {
+		problem.Field(c, "images", problem.FieldRequired, "At least one image is required")
+		return
+	}
+	files := form.File["images"]
+	if len(files) > maxBlogImagesPerUpload {
+		problem.Field(c, "images", problem.FieldInvalid, fmt.Sprintf("At most %d images per upload", maxBlogImagesPerUpload))
+		return
+	}
+
+	// Erst alle Dateien prüfen, damit nichts halb gespeichert wird
+	uploads := make([]*validatedUpload, len(files))
+	for i, file := range files {
+		upload, uploadErr := validateUpload(file, blogImageUpload)
+		if uploadErr != nil {
+			uploadErr.respond(c, "images", file.Filename+": "+uploadErr.Message)
+			return
+		}
+		uploads[i] = upload
+	}
+
+	ctx := c.Request.Context()
+	images := make([]models.BlogImage, 0, len(uploads))
+
+	// Bei einem Fehler die schon gespeicherten Dateien wieder entfernen
+	var stored []string
+	cleanup := func() {
+		for _, key := range stored {
+			storage.Store.Delete(ctx, key)
+		}
+	}
+
+	for _, upload := range uploads {
+		image := models.BlogImage{
+			ID:       uuid.New().String(),
+			BlogID:   blog.ID,
+			Filename: filepath.Base(upload.File.Filename),
+			MimeType: upload.MimeType,
+			Size:     upload.File.Size,
+		}
+
+		key := blogImageKey(blog.ID, image.ID, upload.Ext)
+		f, err := upload.File.Open()
+		if err != nil {
+			cleanup()
+			problem.Internal(c, problem.CodeStorage, err, "Failed to read image")
+			return
+		}
+		err = storage.Store.Save(ctx, key, f, upload.File.Size, upload.MimeType)
+		f.Close()
+		if err != nil {
+			cleanup()
+			problem.Internal(c, problem.CodeStorage, err, "Failed to save image")
+			return
+		}
+		stored = append(stored, key)
+
+		image.URL = "/" + key
+		images = append(images, image)
+	}
+
+	tx := database.DB.Begin()
+	if err := tx.Create(&images).Error; err != nil {
+		tx.Rollback()
+		cleanup()
+		problem.Database(c, err, "Failed to save images")
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		cleanup()
+		problem.Database(c, err, "Failed to save images")
+		return
+	}
+
+	for i := range images {
+		images[i].URL = withCDNPrefix(images[i].URL)
+	}
+	c.JSON(http.StatusCreated, images)
+}
+
+func DeleteBlogImage(c *gin.Context) {
+	var image models.BlogImage
+	if err := database.DB.First(&image, "id = ? AND blog_id = ?", c.Param("imageId"), c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Image not found")
+		return
+	}
+
+	if err := database.DB.Delete(&image).Error; err != nil {
+		problem.Database(c, err, "Failed to delete image")
+		return
+	}
+
+	storage.Store.Delete(c.Request.Context(), image.URL)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Image deleted"})
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gewichtung der Gemeinsamkeiten für verwandte Blogs
+const (
+	relatedCategoryWeight = 3.0
+	relatedTagWeight      = 2.0
+	relatedAuthorWeight   = 1.0
+	// Ein neuer Blog bekommt bis zu diesen Bonus, nach relatedRecencyDays
+	// Tagen ist es noch die Hälfte
+	relatedRecencyWeight = 1.0
+	relatedRecencyDays   = 90.0
+)
+
+// sharedCounts zählt für jeden anderen Blog, wie viele Einträge er in einer
+// Verknüpfungstabelle mit dem Blog gemeinsam hat.
+func sharedCounts(joinTable, column string, blogID uint) (map[uint]int64, error) {
+	var rows []struct {
+		BlogID uint
+		Count  int64
+	}
+	err := database.DB.Table(joinTable).
+		Select("blog_id, COUNT(*) AS count").
+		Where(column+" IN (?)", database.DB.Table(joinTable).Select(column).Where("blog_id = ?", blogID)).
+		Where("blog_id <> ?", blogID).
+		Group("blog_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.BlogID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetRelatedBlogs liefert veröffentlichte Blogs, die mit dem Blog Kategorien,
+// Tags oder Authors teilen. Bei gleicher Punktzahl gewinnt der neuere Blog.
+func GetRelatedBlogs(c *gin.Context) {
+	var blog models.Blog
+	if err := database.DB.Scopes(visibleBlogs(c)).First(&blog, "blogs.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Blog not found")
+		return
+	}
+
+	limit := 5
+	if value := c.Query("limit"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= 20 {
+			limit = n
+		}
+	}
+
+	scores := map[uint]float64{}
+	for _, shared := range []struct {
+		JoinTable string
+		Column    string
+		Weight    float64
+	}{
+		{"blog_categories", "category_id", relatedCategoryWeight},
+		{"blog_tags", "tag_id", relatedTagWeight},
+		{"blog_authors", "user_id", relatedAuthorWeight},
+	} {
+		counts, err := sharedCounts(shared.JoinTable, shared.Column, blog.ID)
+		if err != nil {
+			problem.Database(c, err, "Failed to load related blogs")
+			return
+		}
+		for id, count := range counts {
+			scores[id] += float64(count) * shared.Weight
+		}
+	}
+
+	related := []models.Blog{}
+	if len(scores) == 0 {
+		c.JSON(http.StatusOK, related)
+		return
+	}
+
+	ids := make([]uint, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+
+	// Zum Ranken reichen ID und Datum, erst die besten limit Blogs werden
+	// vollständig geladen
+	var candidates []struct {
+		ID          uint
+		PublishedAt *time.Time
+		CreatedAt   time.Time
+	}
+	if err := database.DB.Model(&models.Blog{}).Select("blogs.id, blogs.published_at, blogs.created_at").
+		Scopes(publishedBlogs).Where("blogs.id IN ?", ids).Scan(&candidates).Error; err != nil {
+		problem.Database(c, err, "Failed to load related blogs")
+		return
+	}
+
+	now := time.Now()
+	published := make(map[uint]time.Time, len(candidates))
+	ranked := make([]uint, 0, len(candidates))
+	for _, candidate := range candidates {
+		at := candidate.CreatedAt
+		if candidate.PublishedAt != nil {
+			at = *candidate.PublishedAt
+		}
+		published[candidate.ID] = at
+		ageDays := math.Max(now.Sub(at).Hours()/24, 0)
+		scores[candidate.ID] += relatedRecencyWeight * relatedRecencyDays / (relatedRecencyDays + ageDays)
+		ranked = append(ranked, candidate.ID)
+	}
+
+	// Bei gleicher Punktzahl zuerst der neuere Blog, danach die höhere ID,
+	// damit die Reihenfolge nicht von der Datenbank abhängt
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		if !published[a].Equal(published[b]) {
+			return published[a].After(published[b])
+		}
+		return a > b
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	if len(ranked) == 0 {
+		c.JSON(http.StatusOK, related)
+		return
+	}
+
+	var blogs []models.Blog
+	if err := database.DB.Preload("Authors").Preload("Categories").Preload("Tags").Preload("ImageMedia").
+		Where("blogs.id IN ?", ranked).Find(&blogs).Error; err != nil {
+		problem.Database(c, err, "Failed to load related blogs")
+		return
+	}
+	byID := make(map[uint]models.Blog, len(blogs))
+	for _, b := range blogs {
+		byID[b.ID] = b
+	}
+	for _, id := range ranked {
+		if b, ok := byID[id]; ok {
+			addBlogCDNPrefix(&b)
+			related = append(related, b)
+		}
+	}
+	c.JSON(http.StatusOK, related)
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/middleware"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/textdiff"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// currentEditor liefert ID und Namen dessen, der gerade speichert.
+func currentEditor(c *gin.Context) (*string, string) {
+	if user := middleware.CurrentUser(c); user != nil {
+		return &user.ID, user.Name
+	}
+	if session := middleware.CurrentSession(c); session != nil {
+		return nil, session.Subject
+	}
+	if apiKey := middleware.CurrentAPIKey(c); apiKey != nil {
+		return nil, "API key " + apiKey.Name
+	}
+	return nil, ""
+}
+
+// newBlogRevision erzeugt aus einem Blog eine Revision. Authors, Categories
+// und Tags müssen geladen sein.
+func newBlogRevision(blog *models.Blog, note string) models.BlogRevision {
+	revision := models.BlogRevision{
+		BlogID:      blog.ID,
+		Title:       blog.Title,
+		Slug:        blog.Slug,
+		Excerpt:     blog.Excerpt,
+		Content:     blog.Content,
+		Image:       blog.Image,
+		Pinned:      blog.Pinned,
+		Status:      blog.Status,
+		PublishedAt: blog.PublishedAt,
+		AuthorIDs:   []string{},
+		CategoryIDs: []string{},
+		Tags:        []string{},
+		Note:        note,
+	}
+	for _, author := range blog.Authors {
+		revision.AuthorIDs = append(revision.AuthorIDs, author.ID)
+	}
+	for _, category := range blog.Categories {
+		revision.CategoryIDs = append(revision.CategoryIDs, category.ID)
+	}
+	for _, tag := range blog.Tags {
+		revision.Tags = append(revision.Tags, tag.Name)
+	}
+	return revision
+}
+
+// loadBlogWithRelations lädt einen Blog samt Verknüpfungen neu, bei
+// Bedarf innerhalb einer Transaktion.
+func loadBlogWithRelations(db *gorm.DB, blog *models.Blog) error {
+	return db.Preload("Authors").Preload("Categories").Preload("Tags").Preload("ImageMedia").First(blog, blog.ID).Error
+}
+
+// saveBlogRevision lädt den Blog über db neu und speichert den Stand als
+// Revision. Danach sind in blog alle Verknüpfungen geladen.
+func saveBlogRevision(db *gorm.DB, c *gin.Context, blog *models.Blog, note string) error {
+	if err := loadBlogWithRelations(db, blog); err != nil {
+		return err
+	}
+	revision := newBlogRevision(blog, note)
+	revision.EditorID, revision.EditorName = currentEditor(c)
+	return db.Create(&revision).Error
+}
+
+// ensureBaseRevision sichert den Stand vor der ersten Bearbeitung. Blogs von
+// vor der Einführung der Revisionen haben sonst keinen Weg zurück.
+func ensureBaseRevision(tx *gorm.DB, blogID uint) error {
+	var count int64
+	if err := tx.Model(&models.BlogRevision{}).Where("blog_id = ?", blogID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	current := models.Blog{ID: blogID}
+	if err := loadBlogWithRelations(tx, &current); err != nil {
+		return err
+	}
+	revision := newBlogRevision(&current, "Before first edit")
+	return tx.Create(&revision).Error
+}
+
+func findBlogRevision(c *gin.Context, revisionID string) (*models.BlogRevision, bool) {
+	var revision models.BlogRevision
+	if err := database.DB.First(&revision, "id = ? AND blog_id = ?", revisionID, c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Revision not found")
+		return nil, false
+	}
+	return &revision, true
+}
+
+func GetBlogRevisions(c *gin.Context) {
+	revisions := []models.BlogRevision{}
+	if err := database.DB.Select("id, blog_id, title, slug, status, editor_id, editor_name, note, created_at").
+		Where("blog_id = ?", c.Param("id")).
+		Order("id DESC").
+		Find(&revisions).Error; err != nil {
+		problem.Database(c, err, "Failed to load revisions")
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+func GetBlogRevision(c *gin.Context) {
+	revision, ok := findBlogRevision(c, c.Param("revisionId"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffBlogRevisions vergleicht ?from= mit ?to=. Ohne to wird gegen die
+// neueste Revision verglichen.
+func DiffBlogRevisions(c *gin.Context) {
+	fromID := c.Query("from")
+	if fromID == "" {
+		problem.AbortCode(c, http.StatusBadRequest, problem.CodeInvalidQuery, "from is required")
+		return
+	}
+	from, ok := findBlogRevision(c, fromID)
+	if !ok {
+		return
+	}
+
+	var to *models.BlogRevision
+	if toID := c.Query("to"); toID != "" {
+		if to, ok = findBlogRevision(c, toID); !ok {
+			return
+		}
+	} else {
+		to = &models.BlogRevision{}
+		if err := database.DB.Where("blog_id = ?", c.Param("id")).Order("id DESC").First(to).Error; err != nil {
+			problem.Lookup(c, err, "Revision not found")
+			return
+		}
+	}
+
+	changes := gin.H{}
+	for field, values := range map[string][2]string{
+		"title":      {from.Title, to.Title},
+		"slug":       {from.Slug, to.Slug},
+		"image":      {from.Image, to.Image},
+		"status":     {from.Status, to.Status},
+		"pinned":     {fmt.Sprint(from.Pinned), fmt.Sprint(to.Pinned)},
+		"authors":    {strings.Join(from.AuthorIDs, ","), strings.Join(to.AuthorIDs, ",")},
+		"categories": {strings.Join(from.CategoryIDs, ","), strings.Join(to.CategoryIDs, ",")},
+		"tags":       {strings.Join(from.Tags, ","), strings.Join(to.Tags, ",")},
+	} {
+		if values[0] != values[1] {
+			changes[field] = gin.H{"from": values[0], "to": values[1]}
+		}
+	}
+	if !timesEqual(from, to) {
+		changes["published_at"] = gin.H{"from": from.PublishedAt, "to": to.PublishedAt}
+	}
+
+	// Längere Texte zeilenweise vergleichen
+	for field, values := range map[string][2]string{
+		"excerpt": {from.Excerpt, to.Excerpt},
+		"content": {from.Content, to.Content},
+	} {
+		if lines := textdiff.Lines(values[0], values[1]); textdiff.Changed(lines) {
+			changes[field] = lines
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from.ID,
+		"to":      to.ID,
+		"changes": changes,
+	})
+}
+
+func timesEqual(a, b *models.BlogRevision) bool {
+	if a.PublishedAt == nil || b.PublishedAt == nil {
+		return a.PublishedAt == b.PublishedAt
+	}
+	return a.PublishedAt.Equal(*b.PublishedAt)
+}
+
+func RestoreBlogRevision(c *gin.Context) {
+	var blog models.Blog
+	if err := database.DB.First(&blog, "blogs.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Blog not found")
+		return
+	}
+	revision, ok := findBlogRevision(c, c.Param("revisionId"))
+	if !ok {
+		return
+	}
+
+	// Der alte Slug könnte inzwischen einem anderen Blog gehören
+	oldSlug := blog.Slug
+	if taken, err := blogSlugTaken(database.DB, revision.Slug, blog.ID); err != nil {
+		problem.Database(c, err, "Failed to check slug")
+		return
+	} else if taken {
+		problem.Taken(c, "slug", "Slug is already used by another blog")
+		return
+	}
+
+	blog.Title = revision.Title
+	blog.Slug = revision.Slug
+	blog.Excerpt = revision.Excerpt
+	blog.Content = revision.Content
+	blog.Image = revision.Image
+	blog.Pinned = revision.Pinned
+	blog.Status = revision.Status
+	blog.PublishedAt = revision.PublishedAt
+
+	// Bild aus der Mediathek wieder verknüpfen, falls es noch existiert
+	blog.ImageMediaID = nil
+	var media models.Media
+	if err := database.DB.Where("url = ?", revision.Image).First(&media).Error; err == nil {
+		blog.ImageMediaID = &media.ID
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Database(c, err, "Failed to load media")
+		return
+	}
+
+	if err := applyBlogOutline(&blog); err != nil {
+		problem.Internal(c, problem.CodeInternal, err, "Failed to parse content")
+		return
+	}
+
+	tx := database.DB.Begin()
+
+	if err := tx.Save(&blog).Error; err != nil {
+		tx.Rollback()
+		if problem.IsDuplicate(err) {
+			problem.Taken(c, "slug", "Slug is already used by another blog")
+		} else {
+			problem.Database(c, err, "Failed to restore revision")
+		}
+		return
+	}
+
+	if err := recordBlogSlugChange(tx, blog.ID, oldSlug, blog.Slug); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to save slug history")
+		return
+	}
+
+	// Gelöschte Authors und Categories werden übersprungen
+	authors := []models.User{}
+	if err := tx.Where("id IN ?", revision.AuthorIDs).Find(&authors).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to load authors")
+		return
+	}
+	if err := tx.Model(&blog).Association("Authors").Replace(authors); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to restore authors")
+		return
+	}
+
+	categories := []models.Category{}
+	if err := tx.Where("id IN ?", revision.CategoryIDs).Find(&categories).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to load categories")
+		return
+	}
+	if err := tx.Model(&blog).Association("Categories").Replace(categories); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to restore categories")
+		return
+	}
+
+	// Revisionen von vor der Einführung der Tags lassen die Tags unverändert
+	if revision.Tags != nil {
+		tags, err := tagsByName(tx, revision.Tags)
+		if err == nil {
+			err = tx.Model(&blog).Association("Tags").Replace(tags)
+		}
+		if err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to restore tags")
+			return
+		}
+	}
+
+	if err := saveBlogRevision(tx, c, &blog, fmt.Sprintf("Restored revision %d", revision.ID)); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to save blog revision")
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to restore revision")
+		return
+	}
+
+	addBlogCDNPrefix(&blog)
+	c.JSON(http.StatusOK, blog)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"strconv"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/slug"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// blogSlugTaken prüft, ob ein Slug schon von einem anderen Blog benutzt wird,
+// aktuell oder früher. Alte Slugs bleiben reserviert, damit ihre Weiterleitung
+// nicht auf einen fremden Blog zeigt.
+func blogSlugTaken(db *gorm.DB, s string, blogID uint) (bool, error) {
+	var count int64
+	if err := db.Model(&models.Blog{}).Where("slug = ? AND id <> ?", s, blogID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	if err := db.Model(&models.BlogSlug{}).Where("slug = ? AND blog_id <> ?", s, blogID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// uniqueBlogSlug erzeugt einen freien Slug aus dem Titel, bei Bedarf mit
+// angehängter Nummer ("mein-blog-2").
+func uniqueBlogSlug(title string) (string, error) {
+	base := slug.Make(title)
+	if base == "" {
+		base = "blog"
+	}
+	s := base
+	for i := 2; ; i++ {
+		taken, err := blogSlugTaken(database.DB, s, 0)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return s, nil
+		}
+		s = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// checkBlogSlug normalisiert einen vom Client gesendeten Slug wie bei
+// Kategorien und Serien und prüft, ob er frei ist. Bei einem Fehler ist die
+// Antwort schon geschrieben.
+func checkBlogSlug(c *gin.Context, value string, blogID uint) (string, bool) {
+	s := slug.Make(value)
+	if s == "" {
+		problem.Field(c, "slug", problem.FieldInvalid, "Slug is invalid")
+		return "", false
+	}
+	taken, err := blogSlugTaken(database.DB, s, blogID)
+	if err != nil {
+		problem.Database(c, err, "Failed to check slug")
+		return "", false
+	}
+	if taken {
+		problem.Taken(c, "slug", "Slug is already used by another blog")
+		return "", false
+	}
+	return s, true
+}
+
+// recordBlogSlugChange merkt sich den alten Slug eines Blogs. Kehrt ein Blog
+// zu einem früheren Slug zurück, fällt dieser aus der Historie.
+func recordBlogSlugChange(db *gorm.DB, blogID uint, oldSlug, newSlug string) error {
+	if oldSlug == newSlug || oldSlug == "" {
+		return nil
+	}
+	if err := db.Where("blog_id = ? AND slug = ?", blogID, newSlug).Delete(&models.BlogSlug{}).Error; err != nil {
+		return err
+	}
+	return db.Create(&models.BlogSlug{BlogID: blogID, Slug: oldSlug}).Error
+}
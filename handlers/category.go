@@ -1,42 +1,268 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"PortfolioAPI/database"
 	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/slug"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// categoryTree hängt Kategorien an ihre Eltern und liefert die Wurzeln.
+// Kategorien mit unbekannten Eltern werden zu Wurzeln.
+func categoryTree(categories []models.Category) []*models.Category {
+	byID := make(map[string]*models.Category, len(categories))
+	for i := range categories {
+		categories[i].Children = []*models.Category{}
+		byID[categories[i].ID] = &categories[i]
+	}
+
+	roots := []*models.Category{}
+	for i := range categories {
+		category := &categories[i]
+		if category.ParentID != nil {
+			if parent, ok := byID[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, category)
+				continue
+			}
+		}
+		roots = append(roots, category)
+	}
+	return roots
+}
+
+// categoryDescendantIDs liefert die ID einer Kategorie und aller Unterkategorien.
+func categoryDescendantIDs(categories []models.Category, rootID string) []string {
+	children := map[string][]string{}
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []string{rootID}
+	seen := map[string]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// uniqueCategorySlug erzeugt einen freien Slug aus dem Namen.
+func uniqueCategorySlug(name, categoryID string) (string, error) {
+	base := slug.Make(name)
+	if base == "" {
+		base = "category"
+	}
+	s := base
+	for i := 2; ; i++ {
+		taken, err := categorySlugTaken(s, categoryID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return s, nil
+		}
+		s = base + "-" + strconv.Itoa(i)
+	}
+}
+
+func categorySlugTaken(s, categoryID string) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.Category{}).Where("slug = ? AND id <> ?", s, categoryID).Count(&count).Error
+	return count > 0, err
+}
+
+// checkCategorySlug normalisiert einen vom Client gesendeten Slug und prüft,
+// ob er frei ist. Bei einem Fehler ist die Antwort schon geschrieben.
+func checkCategorySlug(c *gin.Context, value, categoryID string) (string, bool) {
+	s := slug.Make(value)
+	if s == "" {
+		problem.Field(c, "slug", problem.FieldInvalid, "Slug is invalid")
+		return "", false
+	}
+	taken, err := categorySlugTaken(s, categoryID)
+	if err != nil {
+		problem.Database(c, err, "Failed to check slug")
+		return "", false
+	}
+	if taken {
+		problem.Taken(c, "slug", "Slug is already in use")
+		return "", false
+	}
+	return s, true
+}
+
+// applyCategoryParent setzt die Elternkategorie. Eine Kategorie darf nicht
+// unter sich selbst oder einer ihrer Unterkategorien hängen. Ungültige
+// Eltern kommen als Feldfehler zurück, Datenbankfehler als error.
+func applyCategoryParent(category *models.Category, parentID string) (*problem.FieldError, error) {
+	if parentID == "" {
+		category.ParentID = nil
+		return nil, nil
+	}
+
+	var parent models.Category
+	if err := database.DB.First(&parent, "id = ?", parentID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return &problem.FieldError{Field: "parent_id", Code: problem.FieldInvalid, Message: "Parent category not found"}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if category.ID != "" {
+		var all []models.Category
+		if err := database.DB.Find(&all).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range categoryDescendantIDs(all, category.ID) {
+			if id == parent.ID {
+				return &problem.FieldError{Field: "parent_id", Code: problem.FieldInvalid, Message: "Category cannot be moved below itself"}, nil
+			}
+		}
+	}
+
+	category.ParentID = &parent.ID
+	return nil, nil
+}
+
+// categoryListSpec legt Filter und Sortierung für GET /categories fest.
+var categoryListSpec = listSpec{
+	Table: "categories",
+	Sorts: []string{"name", "slug", "created_at"},
+	Filters: map[string]filterField{
+		"name":       {Column: "name"},
+		"slug":       {Column: "slug"},
+		"parent_id":  {Column: "parent_id"},
+		"created_at": {Column: "created_at", Type: filterTime},
+	},
+	DefaultSort: []sortKey{{"name", false}},
+}
+
+// GetCategories liefert die Kategorien als Baum, mit ?flat=true als Liste.
+// Beim Baum werden die Wurzeln paginiert und gefiltert, jeweils mit allen
+// Unterkategorien.
 func GetCategories(c *gin.Context) {
 	categories := []models.Category{}
-	database.DB.Order("name ASC").Find(&categories)
-	c.JSON(http.StatusOK, categories)
+	query, keys, err := listQuery(c, categoryListSpec, database.DB)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if c.Query("flat") == "true" {
+		if err := paginate(c, query, keys, &categories); err != nil {
+			respondPaginationError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, categories)
+		return
+	}
+
+	roots := []models.Category{}
+	if err := paginate(c, query.Where("categories.parent_id IS NULL"), keys, &roots); err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if err := database.DB.Order("name ASC").Find(&categories).Error; err != nil {
+		problem.Database(c, err, "Failed to load categories")
+		return
+	}
+
+	tree := map[string]*models.Category{}
+	for _, node := range categoryTree(categories) {
+		tree[node.ID] = node
+	}
+	result := []*models.Category{}
+	for _, root := range roots {
+		if node, ok := tree[root.ID]; ok {
+			result = append(result, node)
+		}
+	}
+	c.JSON(http.StatusOK, result)
 }
 
+// GetCategory findet eine Kategorie über ID oder Slug, samt Unterkategorien.
 func GetCategory(c *gin.Context) {
 	var category models.Category
-	if err := database.DB.First(&category, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	if err := database.DB.First(&category, "id = ? OR slug = ?", c.Param("id"), c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Category not found")
+		return
+	}
+
+	categories := []models.Category{}
+	if err := database.DB.Order("name ASC").Find(&categories).Error; err != nil {
+		problem.Database(c, err, "Failed to load categories")
 		return
 	}
+	for _, node := range categoryTree(categories) {
+		if found := findCategoryNode(node, category.ID); found != nil {
+			c.JSON(http.StatusOK, found)
+			return
+		}
+	}
 	c.JSON(http.StatusOK, category)
 }
 
+func findCategoryNode(node *models.Category, id string) *models.Category {
+	if node.ID == id {
+		return node
+	}
+	for _, child := range node.Children {
+		if found := findCategoryNode(child, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
 func CreateCategory(c *gin.Context) {
 	name := c.PostForm("name")
+	categorySlug := c.PostForm("slug")
 	if name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		problem.Field(c, "name", problem.FieldRequired, "Name is required")
 		return
 	}
 
 	category := models.Category{
-		Name: name,
+		Name:        name,
+		Description: c.PostForm("description"),
+	}
+
+	if categorySlug == "" {
+		generated, err := uniqueCategorySlug(name, "")
+		if err != nil {
+			problem.Database(c, err, "Failed to generate slug")
+			return
+		}
+		category.Slug = generated
+	} else {
+		normalized, ok := checkCategorySlug(c, categorySlug, "")
+		if !ok {
+			return
+		}
+		category.Slug = normalized
+	}
+
+	if fieldErr, err := applyCategoryParent(&category, c.PostForm("parent_id")); err != nil {
+		problem.Database(c, err, "Failed to load parent category")
+		return
+	} else if fieldErr != nil {
+		problem.Invalid(c, *fieldErr)
+		return
 	}
 
 	if err := database.DB.Create(&category).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
+		problem.Database(c, err, "Failed to create category")
 		return
 	}
 
@@ -46,7 +272,7 @@ func CreateCategory(c *gin.Context) {
 func UpdateCategory(c *gin.Context) {
 	var category models.Category
 	if err := database.DB.First(&category, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		problem.Lookup(c, err, "Category not found")
 		return
 	}
 
@@ -54,9 +280,29 @@ func UpdateCategory(c *gin.Context) {
 	if name != "" {
 		category.Name = name
 	}
+	if categorySlug := c.PostForm("slug"); categorySlug != "" {
+		normalized, ok := checkCategorySlug(c, categorySlug, category.ID)
+		if !ok {
+			return
+		}
+		category.Slug = normalized
+	}
+	if description, ok := c.GetPostForm("description"); ok {
+		category.Description = description
+	}
+	// Leere parent_id macht die Kategorie zur Wurzel
+	if parentID, ok := c.GetPostForm("parent_id"); ok {
+		if fieldErr, err := applyCategoryParent(&category, parentID); err != nil {
+			problem.Database(c, err, "Failed to load parent category")
+			return
+		} else if fieldErr != nil {
+			problem.Invalid(c, *fieldErr)
+			return
+		}
+	}
 
 	if err := database.DB.Save(&category).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
+		problem.Database(c, err, "Failed to update category")
 		return
 	}
 
@@ -66,18 +312,24 @@ func UpdateCategory(c *gin.Context) {
 func DeleteCategory(c *gin.Context) {
 	var category models.Category
 	if err := database.DB.First(&category, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		problem.Lookup(c, err, "Category not found")
 		return
 	}
 
 	// Erst die Verknüpfungen in blog_categories löschen
 	if err := database.DB.Exec("DELETE FROM blog_categories WHERE category_id = ?", category.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category relations"})
+		problem.Database(c, err, "Failed to delete category relations")
+		return
+	}
+
+	// Unterkategorien rücken eine Ebene nach oben
+	if err := database.DB.Model(&models.Category{}).Where("parent_id = ?", category.ID).Update("parent_id", category.ParentID).Error; err != nil {
+		problem.Database(c, err, "Failed to move subcategories")
 		return
 	}
 
 	if err := database.DB.Delete(&category).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+		problem.Database(c, err, "Failed to delete category")
 		return
 	}
 
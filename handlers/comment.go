@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/middleware"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxCommentLength = 5000
+	// Länge der Spalte author_email
+	maxCommentEmailLength = 255
+	// Länge der Spalte user_agent
+	maxUserAgentLength = 500
+	// Verstecktes Feld im Formular, das nur Bots ausfüllen
+	commentHoneypotField = "website"
+)
+
+var commentLinkPattern = regexp.MustCompile(`(?i)https?://|www\.|<a\s`)
+
+// maxCommentLinks kommt aus COMMENT_MAX_LINKS, Standard ist 2. Kommentare
+// mit mehr Links landen als Spam in der Moderation.
+func maxCommentLinks() int {
+	if value := os.Getenv("COMMENT_MAX_LINKS"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return 2
+}
+
+// spamReason prüft einen neuen Kommentar mit einfachen Heuristiken und
+// liefert den Grund, falls er nach Spam aussieht.
+func spamReason(c *gin.Context, content string) string {
+	if c.PostForm(commentHoneypotField) != "" {
+		return "honeypot field filled"
+	}
+	if links := len(commentLinkPattern.FindAllString(content, -1)); links > maxCommentLinks() {
+		return strconv.Itoa(links) + " links"
+	}
+	return ""
+}
+
+// truncateRunes kürzt s auf höchstens n Zeichen.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// hideCommentPrivateFields entfernt Daten, die nur Moderatoren sehen dürfen.
+func hideCommentPrivateFields(comment *models.Comment) {
+	comment.AuthorEmail = ""
+	comment.IP = ""
+	comment.UserAgent = ""
+	comment.SpamReason = ""
+}
+
+// commentTree hängt Antworten an ihre Eltern. Antworten, deren Eltern nicht
+// in der Liste sind, werden weggelassen.
+func commentTree(comments []models.Comment) []*models.Comment {
+	byID := make(map[string]*models.Comment, len(comments))
+	for i := range comments {
+		comments[i].Replies = []*models.Comment{}
+		byID[comments[i].ID] = &comments[i]
+	}
+
+	roots := []*models.Comment{}
+	for i := range comments {
+		comment := &comments[i]
+		if comment.ParentID == nil {
+			roots = append(roots, comment)
+		} else if parent, ok := byID[*comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, comment)
+		}
+	}
+	return roots
+}
+
+func GetBlogComments(c *gin.Context) {
+	var blog models.Blog
+	if err := database.DB.Scopes(visibleBlogs(c)).First(&blog, "blogs.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Blog not found")
+		return
+	}
+
+	comments := []models.Comment{}
+	if err := database.DB.Where("blog_id = ? AND status = ?", blog.ID, models.CommentStatusApproved).
+		Order("created_at ASC").Find(&comments).Error; err != nil {
+		problem.Database(c, err, "Failed to load comments")
+		return
+	}
+	for i := range comments {
+		hideCommentPrivateFields(&comments[i])
+	}
+	c.JSON(http.StatusOK, commentTree(comments))
+}
+
+func CreateComment(c *gin.Context) {
+	var blog models.Blog
+	if err := database.DB.Scopes(publishedBlogs).First(&blog, "blogs.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Blog not found")
+		return
+	}
+
+	name := strings.TrimSpace(c.PostForm("name"))
+	email := strings.TrimSpace(c.PostForm("email"))
+	content := strings.TrimSpace(c.PostForm("content"))
+	parentID := c.PostForm("parent_id")
+
+	comment := models.Comment{
+		BlogID:    blog.ID,
+		IP:        c.ClientIP(),
+		UserAgent: truncateRunes(c.Request.UserAgent(), maxUserAgentLength),
+	}
+
+	// Eingeloggte User kommentieren mit ihrem Profil und ohne Moderation
+	if user := middleware.CurrentUser(c); user != nil {
+		comment.UserID = &user.ID
+		name = user.Name
+		if user.Email != nil {
+			email = *user.Email
+		}
+		comment.Status = models.CommentStatusApproved
+	}
+
+	var missing []problem.FieldError
+	if name == "" {
+		missing = append(missing, problem.FieldError{Field: "name", Code: problem.FieldRequired, Message: "Name is required"})
+	}
+	if content == "" {
+		missing = append(missing, problem.FieldError{Field: "content", Code: problem.FieldRequired, Message: "Content is required"})
+	}
+	if len(missing) > 0 {
+		problem.Invalid(c, missing...)
+		return
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		problem.Field(c, "name", problem.FieldTooLong, "Name is too long")
+		return
+	}
+	if utf8.RuneCountInString(content) > maxCommentLength {
+		problem.Field(c, "content", problem.FieldTooLong, "Content is too long (max "+strconv.Itoa(maxCommentLength)+" characters)")
+		return
+	}
+	if email != "" {
+		// Nur die Adresse speichern, nicht "Name <adresse>"
+		addr, err := mail.ParseAddress(email)
+		if err != nil {
+			problem.Field(c, "email", problem.FieldInvalid, "Invalid email")
+			return
+		}
+		email = addr.Address
+		if utf8.RuneCountInString(email) > maxCommentEmailLength {
+			problem.Field(c, "email", problem.FieldTooLong, "Email is too long")
+			return
+		}
+	}
+
+	// Antworten nur auf freigegebene Kommentare desselben Blogs
+	if parentID != "" {
+		var parent models.Comment
+		if err := database.DB.Where("id = ? AND blog_id = ? AND status = ?", parentID, blog.ID, models.CommentStatusApproved).
+			First(&parent).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			problem.Field(c, "parent_id", problem.FieldInvalid, "Parent comment not found")
+			return
+		} else if err != nil {
+			problem.Database(c, err, "Failed to load parent comment")
+			return
+		}
+		comment.ParentID = &parent.ID
+	}
+
+	comment.AuthorName = name
+	comment.AuthorEmail = email
+	comment.Content = content
+	if reason := spamReason(c, content); reason != "" {
+		comment.Status = models.CommentStatusSpam
+		comment.SpamReason = reason
+	}
+
+	if err := database.DB.Create(&comment).Error; err != nil {
+		problem.Database(c, err, "Failed to save comment")
+		return
+	}
+
+	// Bots sollen nicht erfahren, dass sie als Spam erkannt wurden
+	if comment.Status == models.CommentStatusSpam {
+		comment.Status = models.CommentStatusPending
+	}
+	hideCommentPrivateFields(&comment)
+	c.JSON(http.StatusCreated, comment)
+}
+
+// commentListSpec legt Filter und Sortierung für GET /comments fest.
+var commentListSpec = listSpec{
+	Table: "comments",
+	Sorts: []string{"created_at", "author_name"},
+	Filters: map[string]filterField{
+		"blog_id":      {Column: "blog_id", Type: filterInt},
+		"parent_id":    {Column: "parent_id"},
+		"user_id":      {Column: "user_id"},
+		"author_name":  {Column: "author_name"},
+		"author_email": {Column: "author_email"},
+		"ip":           {Column: "ip"},
+		"created_at":   {Column: "created_at", Type: filterTime},
+	},
+	DefaultSort: []sortKey{{"created_at", true}},
+}
+
+// GetComments ist die Moderationsliste, standardmäßig mit den offenen
+// Kommentaren. Mit ?status= und ?blog_id= lässt sie sich filtern.
+func GetComments(c *gin.Context) {
+	status := c.DefaultQuery("status", models.CommentStatusPending)
+	if !models.IsValidCommentStatus(status) {
+		problem.AbortCode(c, http.StatusBadRequest, problem.CodeInvalidQuery, "Invalid status")
+		return
+	}
+
+	comments := []models.Comment{}
+	query := database.DB.Where("status = ?", status)
+	if blogID := c.Query("blog_id"); blogID != "" {
+		query = query.Where("blog_id = ?", blogID)
+	}
+	query, keys, err := listQuery(c, commentListSpec, query)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if err := paginate(c, query, keys, &comments); err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+func setCommentStatus(c *gin.Context, status string) {
+	var comment models.Comment
+	if err := database.DB.First(&comment, "id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Comment not found")
+		return
+	}
+
+	comment.Status = status
+	if status != models.CommentStatusSpam {
+		comment.SpamReason = ""
+	}
+	if err := database.DB.Save(&comment).Error; err != nil {
+		problem.Database(c, err, "Failed to update comment")
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+func ApproveComment(c *gin.Context) {
+	setCommentStatus(c, models.CommentStatusApproved)
+}
+
+func MarkCommentSpam(c *gin.Context) {
+	setCommentStatus(c, models.CommentStatusSpam)
+}
+
+// DeleteComment löscht einen Kommentar samt allen Antworten darauf.
+func DeleteComment(c *gin.Context) {
+	var comment models.Comment
+	if err := database.DB.First(&comment, "id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Comment not found")
+		return
+	}
+
+	ids := []string{comment.ID}
+	for parents := ids; len(parents) > 0; {
+		var children []string
+		if err := database.DB.Model(&models.Comment{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error; err != nil {
+			problem.Database(c, err, "Failed to load replies")
+			return
+		}
+		ids = append(ids, children...)
+		parents = children
+	}
+
+	if err := database.DB.Where("id IN ?", ids).Delete(&models.Comment{}).Error; err != nil {
+		problem.Database(c, err, "Failed to delete comment")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted", "deleted": len(ids)})
+}
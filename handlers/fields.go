@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// computedField ist ein Feld der Antwort ohne eigene Spalte, z.B. images.
+// Columns sind die Spalten, aus denen es berechnet wird. DetailOnly-Felder
+// gibt es nur beim einzelnen Eintrag, nicht in der Liste.
+type computedField struct {
+	Columns    []string
+	Preload    string
+	DetailOnly bool
+}
+
+// includeFunc lädt eine Relation für ?include=.
+type includeFunc func(c *gin.Context, query *gorm.DB) *gorm.DB
+
+func preloadInclude(name string) includeFunc {
+	return func(_ *gin.Context, query *gorm.DB) *gorm.DB {
+		return query.Preload(name)
+	}
+}
+
+// fieldSpec legt fest, welche Felder ?fields= und welche Relationen
+// ?include= auswählen dürfen. Fields heißen wie ihre Spalten.
+type fieldSpec struct {
+	Table           string
+	Fields          []string
+	Computed        map[string]computedField
+	Includes        map[string]includeFunc
+	DefaultIncludes []string
+
+	// list ist gesetzt, wenn die Spec für eine Liste gilt
+	list bool
+}
+
+// forList liefert die Spec für den Listen-Endpunkt, in dem
+// DetailOnly-Felder abgelehnt werden.
+func (spec fieldSpec) forList() fieldSpec {
+	spec.list = true
+	return spec
+}
+
+// selectFields wertet ?fields= und ?include= aus:
+//
+//	?fields=id,title,slug   nur diese Felder laden und ausgeben
+//	?include=authors,tags   nur diese Relationen laden, leer für keine
+//
+// Ohne ?fields werden alle Spalten geladen, ohne ?include die
+// DefaultIncludes. Mit ?fields, aber ohne ?include, werden nur die
+// DefaultIncludes geladen, die in ?fields stehen. Die Spalten aus keys
+// werden immer mitgeladen, damit paginate die Cursor bauen kann. Zurück
+// kommen die Felder, auf die sparseJSON die Antwort kürzt, oder nil für die
+// vollständige Antwort.
+func selectFields(c *gin.Context, spec fieldSpec, query *gorm.DB, keys []sortKey) (*gorm.DB, []string, error) {
+	value, hasFields := c.GetQuery("fields")
+	fields := splitFilterList(value)
+	if hasFields && len(fields) == 0 {
+		return nil, nil, fmt.Errorf("%w: fields must not be empty", errInvalidQuery)
+	}
+
+	includes := spec.DefaultIncludes
+	if value, ok := c.GetQuery("include"); ok {
+		includes = splitFilterList(value)
+	} else if hasFields {
+		includes = nil
+		for _, name := range spec.DefaultIncludes {
+			if containsString(fields, name) {
+				includes = append(includes, name)
+			}
+		}
+	}
+	for _, name := range includes {
+		include, ok := spec.Includes[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: unknown include %q", errInvalidQuery, name)
+		}
+		query = include(c, query)
+	}
+
+	if !hasFields {
+		for _, field := range spec.Computed {
+			if field.Preload != "" {
+				query = query.Preload(field.Preload)
+			}
+		}
+		return query, nil, nil
+	}
+
+	columns := []string{"id"}
+	for _, key := range keys {
+		columns = append(columns, key.Column)
+	}
+	for _, name := range fields {
+		if containsString(includes, name) {
+			continue
+		}
+		if field, ok := spec.Computed[name]; ok {
+			if field.DetailOnly && spec.list {
+				return nil, nil, fmt.Errorf("%w: field %q is only available for single items", errInvalidQuery, name)
+			}
+			columns = append(columns, field.Columns...)
+			if field.Preload != "" {
+				query = query.Preload(field.Preload)
+			}
+			continue
+		}
+		if !containsString(spec.Fields, name) {
+			return nil, nil, fmt.Errorf("%w: unknown field %q", errInvalidQuery, name)
+		}
+		columns = append(columns, name)
+	}
+
+	selected := make([]string, 0, len(columns))
+	seen := map[string]bool{}
+	for _, column := range columns {
+		if !seen[column] {
+			seen[column] = true
+			selected = append(selected, spec.Table+"."+column)
+		}
+	}
+	return query.Select(strings.Join(selected, ", ")), append(fields, includes...), nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sparseJSON kürzt einen Eintrag oder eine Liste auf die Felder keep.
+// Mit keep == nil bleibt die Antwort unverändert.
+func sparseJSON(v interface{}, keep []string) (interface{}, error) {
+	if keep == nil {
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := func(item map[string]json.RawMessage) map[string]json.RawMessage {
+		result := make(map[string]json.RawMessage, len(keep))
+		for _, key := range keep {
+			if value, ok := item[key]; ok {
+				result[key] = value
+			}
+		}
+		return result
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		result := make([]map[string]json.RawMessage, len(items))
+		for i, item := range items {
+			result[i] = filter(item)
+		}
+		return result, nil
+	}
+
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	return filter(item), nil
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"PortfolioAPI/imaging"
+	"PortfolioAPI/models"
+	"PortfolioAPI/storage"
+)
+
+func withCDNPrefix(path string) string {
+	if path != "" && !strings.HasPrefix(path, "http") {
+		return getCDNURL() + path
+	}
+	return path
+}
+
+// saveMediaVariants erzeugt die responsiven Varianten eines Bildes und
+// speichert sie neben dem Original. Bilder, die sich nicht dekodieren
+// lassen, bleiben ohne Varianten.
+func saveMediaVariants(ctx context.Context, media *models.Media, upload *validatedUpload) error {
+	f, err := upload.File.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	img, err := imaging.Decode(f)
+	if err != nil {
+		return nil
+	}
+	media.Width = img.Bounds().Dx()
+	media.Height = img.Bounds().Dy()
+
+	variants, err := imaging.Variants(img, upload.MimeType)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range variants {
+		key := "media/" + media.ID + "/" + v.Name + v.Ext
+		if err := storage.Store.Save(ctx, key, bytes.NewReader(v.Data), int64(len(v.Data)), v.MimeType); err != nil {
+			return err
+		}
+
+		// Ursprungsformat und WebP einer Größe teilen sich einen Eintrag
+		if len(media.Variants) == 0 || media.Variants[len(media.Variants)-1].Name != v.Name {
+			media.Variants = append(media.Variants, models.ImageVariant{Name: v.Name, Width: v.Width, Height: v.Height})
+		}
+		variant := &media.Variants[len(media.Variants)-1]
+		if v.MimeType == "image/webp" {
+			variant.WebPURL = "/" + key
+		} else {
+			variant.URL = "/" + key
+		}
+	}
+	return nil
+}
+
+// imageSetFor baut aus einem Medium die URLs für srcset-Attribute.
+func imageSetFor(media *models.Media) *models.ImageSet {
+	set := &models.ImageSet{Original: withCDNPrefix(media.URL)}
+
+	var srcset, webpSrcset []string
+	for _, v := range media.Variants {
+		url := withCDNPrefix(v.URL)
+		switch v.Name {
+		case "thumbnail":
+			set.Thumbnail = url
+		case "medium":
+			set.Medium = url
+		case "large":
+			set.Large = url
+		}
+		srcset = append(srcset, fmt.Sprintf("%s %dw", url, v.Width))
+		if v.WebPURL != "" {
+			webpSrcset = append(webpSrcset, fmt.Sprintf("%s %dw", withCDNPrefix(v.WebPURL), v.Width))
+		}
+	}
+
+	if media.Width > 0 {
+		srcset = append(srcset, fmt.Sprintf("%s %dw", set.Original, media.Width))
+	} else {
+		srcset = append(srcset, set.Original)
+	}
+	set.Srcset = strings.Join(srcset, ", ")
+	set.WebPSrcset = strings.Join(webpSrcset, ", ")
+	return set
+}
@@ -1,14 +1,13 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"PortfolioAPI/database"
 	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,9 +18,28 @@ func addLanguageCDNPrefix(language *models.Language) {
 	}
 }
 
+// languageListSpec legt Filter und Sortierung für GET /languages fest.
+var languageListSpec = listSpec{
+	Table: "languages",
+	Sorts: []string{"name", "created_at"},
+	Filters: map[string]filterField{
+		"name":       {Column: "name"},
+		"created_at": {Column: "created_at", Type: filterTime},
+	},
+	DefaultSort: []sortKey{{"name", false}},
+}
+
 func GetLanguages(c *gin.Context) {
 	languages := []models.Language{}
-	database.DB.Find(&languages)
+	query, keys, err := listQuery(c, languageListSpec, database.DB)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if err := paginate(c, query, keys, &languages); err != nil {
+		respondPaginationError(c, err)
+		return
+	}
 	for i := range languages {
 		addLanguageCDNPrefix(&languages[i])
 	}
@@ -31,7 +49,7 @@ func GetLanguages(c *gin.Context) {
 func GetLanguage(c *gin.Context) {
 	var language models.Language
 	if err := database.DB.First(&language, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Language not found"})
+		problem.Lookup(c, err, "Language not found")
 		return
 	}
 	addLanguageCDNPrefix(&language)
@@ -43,38 +61,35 @@ func CreateLanguage(c *gin.Context) {
 	iconURL := c.PostForm("icon")
 
 	if name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		problem.Field(c, "name", problem.FieldRequired, "Name is required")
 		return
 	}
 
+	// Icon hochgeladen oder aus der Mediathek gewählt?
+	icon, ok := mediaFromForm(c, "icon_file", "icon_media_id", languageIconUpload)
+	if !ok {
+		return
+	}
+	media, ok := icon.save(c)
+	if !ok {
+		return
+	}
+	defer icon.cleanup(c)
+
 	language := models.Language{
 		Name: name,
 		Icon: iconURL,
 	}
+	if media != nil {
+		language.Icon = media.URL
+		language.IconMediaID = &media.ID
+	}
 
-	database.DB.Create(&language)
-
-	// Icon hochgeladen?
-	file, err := c.FormFile("icon_file")
-	if err == nil {
-		langDir := filepath.Join("public", "languages", language.ID)
-		if err := os.MkdirAll(langDir, 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create language directory"})
-			return
-		}
-
-		ext := filepath.Ext(file.Filename)
-		filename := fmt.Sprintf("icon%s", ext)
-		filePath := filepath.Join(langDir, filename)
-
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save icon"})
-			return
-		}
-
-		language.Icon = fmt.Sprintf("/languages/%s/%s", language.ID, filename)
-		database.DB.Save(&language)
+	if err := database.DB.Create(&language).Error; err != nil {
+		problem.Database(c, err, "Failed to create language")
+		return
 	}
+	icon.keep()
 
 	addLanguageCDNPrefix(&language)
 	c.JSON(http.StatusCreated, language)
@@ -83,7 +98,7 @@ func CreateLanguage(c *gin.Context) {
 func UpdateLanguage(c *gin.Context) {
 	var language models.Language
 	if err := database.DB.First(&language, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Language not found"})
+		problem.Lookup(c, err, "Language not found")
 		return
 	}
 
@@ -95,36 +110,29 @@ func UpdateLanguage(c *gin.Context) {
 	}
 	if iconURL != "" {
 		language.Icon = iconURL
+		language.IconMediaID = nil
 	}
 
-	// Icon hochgeladen?
-	file, err := c.FormFile("icon_file")
-	if err == nil {
-		langDir := filepath.Join("public", "languages", language.ID)
-		if err := os.MkdirAll(langDir, 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create language directory"})
-			return
-		}
-
-		// Alte Icons löschen
-		matches, _ := filepath.Glob(filepath.Join(langDir, "icon.*"))
-		for _, match := range matches {
-			os.Remove(match)
-		}
-
-		ext := filepath.Ext(file.Filename)
-		filename := fmt.Sprintf("icon%s", ext)
-		filePath := filepath.Join(langDir, filename)
-
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save icon"})
-			return
-		}
-
-		language.Icon = fmt.Sprintf("/languages/%s/%s", language.ID, filename)
+	// Icon hochgeladen oder aus der Mediathek gewählt?
+	icon, ok := mediaFromForm(c, "icon_file", "icon_media_id", languageIconUpload)
+	if !ok {
+		return
+	}
+	media, ok := icon.save(c)
+	if !ok {
+		return
+	}
+	defer icon.cleanup(c)
+	if media != nil {
+		language.Icon = media.URL
+		language.IconMediaID = &media.ID
 	}
 
-	database.DB.Save(&language)
+	if err := database.DB.Save(&language).Error; err != nil {
+		problem.Database(c, err, "Failed to update language")
+		return
+	}
+	icon.keep()
 	addLanguageCDNPrefix(&language)
 	c.JSON(http.StatusOK, language)
 }
@@ -132,7 +140,7 @@ func UpdateLanguage(c *gin.Context) {
 func DeleteLanguage(c *gin.Context) {
 	var language models.Language
 	if err := database.DB.First(&language, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Language not found"})
+		problem.Lookup(c, err, "Language not found")
 		return
 	}
 
@@ -143,20 +151,23 @@ func DeleteLanguage(c *gin.Context) {
 
 	if err := tx.Exec("DELETE FROM project_languages WHERE language_id = ?", langID).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete language projects"})
+		problem.Database(c, err, "Failed to delete language projects")
 		return
 	}
 
 	if err := tx.Delete(&language).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete language"})
+		problem.Database(c, err, "Failed to delete language")
 		return
 	}
 
-	tx.Commit()
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to delete language")
+		return
+	}
 
-	// Icon Ordner löschen
-	os.RemoveAll(filepath.Join("public", "languages", langID))
+	// Alten Icon Ordner löschen (Uploads vor der Mediathek)
+	storage.Store.DeletePrefix(c.Request.Context(), "languages/"+langID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Language deleted"})
 }
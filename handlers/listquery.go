@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"PortfolioAPI/database"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errInvalidQuery wird wie errInvalidPage mit 400 beantwortet.
+var errInvalidQuery = errors.New("Invalid query")
+
+type filterType int
+
+const (
+	filterText filterType = iota
+	filterInt
+	filterTime
+	filterBool
+)
+
+// filterField ist ein Feld, nach dem eine Liste gefiltert werden darf. Column
+// ist eine Spalte der Tabelle. Mit Through wird stattdessen über eine
+// Join-Tabelle gefiltert: Key ist dort die Spalte mit der ID des Eintrags,
+// Column die verknüpfte ID.
+type filterField struct {
+	Column  string
+	Type    filterType
+	Through string
+	Key     string
+}
+
+// listSpec legt pro Liste fest, welche Felder in ?sort= und ?filter[...]
+// erlaubt sind. Alles andere wird mit 400 abgelehnt.
+type listSpec struct {
+	Table       string
+	Sorts       []string
+	Filters     map[string]filterField
+	DefaultSort []sortKey
+}
+
+var filterComparisons = map[string]string{
+	"eq":  "=",
+	"ne":  "<>",
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+var filterParamPattern = regexp.MustCompile(`^filter\[(\w+)\](?:\[(\w+)\])?$`)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// listQuery wendet ?filter[...] auf query an und liefert die Sortierung aus
+// ?sort= für paginate.
+//
+//	?sort=-created_at,title               absteigend mit -, mehrere mit Komma
+//	?filter[author_id]=...                gleich
+//	?filter[created_at][gte]=2024-01-01   eq, ne, gt, gte, lt, lte
+//	?filter[status][in]=draft,archived    Liste mit Komma
+//	?filter[title][contains]=go           nur Textfelder
+//	?filter[published_at][null]=true      IS NULL bzw. IS NOT NULL
+func listQuery(c *gin.Context, spec listSpec, query *gorm.DB) (*gorm.DB, []sortKey, error) {
+	keys, err := parseSort(c.Query("sort"), spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Sortiert, damit gleiche URLs gleiches SQL ergeben
+	params := c.Request.URL.Query()
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, "filter[") {
+			continue
+		}
+		match := filterParamPattern.FindStringSubmatch(name)
+		if match == nil {
+			return nil, nil, fmt.Errorf("%w: malformed filter %q", errInvalidQuery, name)
+		}
+		field, ok := spec.Filters[match[1]]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: unknown filter field %q", errInvalidQuery, match[1])
+		}
+		op := match[2]
+		if op == "" {
+			op = "eq"
+		}
+		for _, value := range params[name] {
+			where, args, err := filterCondition(spec.Table, field, op, value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: filter %q: %v", errInvalidQuery, match[1], err)
+			}
+			query = query.Where(where, args...)
+		}
+	}
+	return query, keys, nil
+}
+
+func parseSort(value string, spec listSpec) ([]sortKey, error) {
+	if value == "" {
+		return spec.DefaultSort, nil
+	}
+	var keys []sortKey
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(part, "-")
+		allowed := false
+		for _, s := range spec.Sorts {
+			allowed = allowed || s == name
+		}
+		if !allowed {
+			return nil, fmt.Errorf("%w: unknown sort field %q", errInvalidQuery, name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			keys = append(keys, sortKey{Column: name, Desc: desc})
+		}
+	}
+	return keys, nil
+}
+
+// filterCondition baut die WHERE-Bedingung für ein Feld. Spaltennamen kommen
+// nur aus der listSpec, Werte immer als Parameter.
+func filterCondition(table string, field filterField, op, value string) (string, []interface{}, error) {
+	if field.Through != "" {
+		var ids []string
+		switch op {
+		case "eq", "ne":
+			ids = []string{value}
+		case "in":
+			ids = splitFilterList(value)
+		default:
+			return "", nil, fmt.Errorf("operator %q not supported", op)
+		}
+		if len(ids) == 0 {
+			return "", nil, errors.New("in expects at least one value")
+		}
+		sub := database.DB.Table(field.Through).Select(field.Key).Where(field.Column+" IN ?", ids)
+		if op == "ne" {
+			return table + ".id NOT IN (?)", []interface{}{sub}, nil
+		}
+		return table + ".id IN (?)", []interface{}{sub}, nil
+	}
+
+	column := table + "." + field.Column
+	switch op {
+	case "null":
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", nil, errors.New("null expects true or false")
+		}
+		if isNull {
+			return column + " IS NULL", nil, nil
+		}
+		return column + " IS NOT NULL", nil, nil
+	case "contains":
+		if field.Type != filterText {
+			return "", nil, errors.New("contains is only supported on text fields")
+		}
+		return column + ` LIKE ? ESCAPE '\\'`, []interface{}{"%" + likeEscaper.Replace(value) + "%"}, nil
+	case "in":
+		var values []interface{}
+		for _, item := range splitFilterList(value) {
+			v, err := filterValue(field.Type, item)
+			if err != nil {
+				return "", nil, err
+			}
+			values = append(values, v)
+		}
+		if len(values) == 0 {
+			return "", nil, errors.New("in expects at least one value")
+		}
+		return column + " IN ?", []interface{}{values}, nil
+	}
+
+	comparison, ok := filterComparisons[op]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown operator %q", op)
+	}
+	if field.Type == filterBool && op != "eq" && op != "ne" {
+		return "", nil, fmt.Errorf("operator %q not supported on boolean fields", op)
+	}
+	v, err := filterValue(field.Type, value)
+	if err != nil {
+		return "", nil, err
+	}
+	return column + " " + comparison + " ?", []interface{}{v}, nil
+}
+
+func filterValue(t filterType, value string) (interface{}, error) {
+	switch t {
+	case filterInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return n, nil
+	case filterBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return b, nil
+	case filterTime:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not a date (RFC 3339 or YYYY-MM-DD)", value)
+	}
+	return value, nil
+}
+
+func splitFilterList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
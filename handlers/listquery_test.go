@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunDB erzeugt SQL für MySQL, ohne sich zu verbinden.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test?parseTime=true",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open dry run db: %v", err)
+	}
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	return db
+}
+
+var testListSpec = listSpec{
+	Table: "blogs",
+	Sorts: []string{"created_at", "title"},
+	Filters: map[string]filterField{
+		"title":      {Column: "title"},
+		"pinned":     {Column: "pinned", Type: filterBool},
+		"word_count": {Column: "word_count", Type: filterInt},
+		"created_at": {Column: "created_at", Type: filterTime},
+		"author_id":  {Column: "user_id", Through: "blog_authors", Key: "blog_id"},
+	},
+	DefaultSort: []sortKey{{"created_at", true}},
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []sortKey
+		invalid bool
+	}{
+		{"", []sortKey{{"created_at", true}}, false},
+		{"title", []sortKey{{"title", false}}, false},
+		{"-created_at, title", []sortKey{{"created_at", true}, {"title", false}}, false},
+		{"title,-title", []sortKey{{"title", false}}, false},
+		{"password", nil, true},
+		{"title,", nil, true},
+		{"--title", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSort(tt.value, testListSpec)
+			if tt.invalid {
+				if !errors.Is(err, errInvalidQuery) {
+					t.Errorf("err = %v, want errInvalidQuery", err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSort = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterCondition(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		field   string
+		op      string
+		value   string
+		where   string
+		args    []interface{}
+		invalid bool
+	}{
+		{"eq text", "title", "eq", "Go", "blogs.title = ?", []interface{}{"Go"}, false},
+		{"ne bool", "pinned", "ne", "true", "blogs.pinned <> ?", []interface{}{true}, false},
+		{"gte int", "word_count", "gte", "100", "blogs.word_count >= ?", []interface{}{int64(100)}, false},
+		{"lt date", "created_at", "lt", "2024-01-01", "blogs.created_at < ?", []interface{}{day}, false},
+		{"contains escapes wildcards", "title", "contains", "50%_", `blogs.title LIKE ? ESCAPE '\\'`, []interface{}{`%50\%\_%`}, false},
+		{"in", "word_count", "in", "1, 2", "blogs.word_count IN ?", []interface{}{[]interface{}{int64(1), int64(2)}}, false},
+		{"null", "created_at", "null", "true", "blogs.created_at IS NULL", nil, false},
+		{"not null", "created_at", "null", "false", "blogs.created_at IS NOT NULL", nil, false},
+		{"unknown operator", "title", "like", "x", "", nil, true},
+		{"contains on int", "word_count", "contains", "1", "", nil, true},
+		{"gt on bool", "pinned", "gt", "true", "", nil, true},
+		{"invalid int", "word_count", "eq", "many", "", nil, true},
+		{"invalid date", "created_at", "gt", "yesterday", "", nil, true},
+		{"invalid null", "created_at", "null", "maybe", "", nil, true},
+		{"empty in", "word_count", "in", " , ", "", nil, true},
+		{"gt through join table", "author_id", "gt", "1", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args, err := filterCondition("blogs", testListSpec.Filters[tt.field], tt.op, tt.value)
+			if tt.invalid {
+				if err == nil {
+					t.Errorf("expected an error, got %q", where)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("filterCondition: %v", err)
+			}
+			if where != tt.where {
+				t.Errorf("where = %q, want %q", where, tt.where)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestListQuery(t *testing.T) {
+	db := dryRunDB(t)
+	tests := []struct {
+		name     string
+		query    string
+		contains []string
+		invalid  bool
+	}{
+		{"no parameters", "", nil, false},
+		{"filter", "filter[title]=Go", []string{"blogs.title = 'Go'"}, false},
+		{"filter with operator", "filter[word_count][gt]=10", []string{"blogs.word_count > 10"}, false},
+		{"through join table", "filter[author_id]=u1", []string{"blogs.id IN (SELECT blog_id FROM `blog_authors` WHERE user_id IN ('u1'))"}, false},
+		{"other parameters are ignored", "page=2&fields=id", nil, false},
+		{"unknown filter field", "filter[password]=x", nil, true},
+		{"malformed filter", "filter[title", nil, true},
+		{"nested filter", "filter[title][eq][x]=Go", nil, true},
+		{"unknown sort field", "sort=password", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _, err := listQuery(testContext("/blogs?"+tt.query), testListSpec, db)
+			if tt.invalid {
+				if !errors.Is(err, errInvalidQuery) {
+					t.Errorf("err = %v, want errInvalidQuery", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("listQuery: %v", err)
+			}
+			stmt := query.Find(&[]models.Blog{}).Statement
+			sql := db.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
+			for _, part := range tt.contains {
+				if !strings.Contains(sql, part) {
+					t.Errorf("SQL %q does not contain %q", sql, part)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/imaging"
+	"PortfolioAPI/middleware"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/storage"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var mediaUpload = uploadRule{MaxSize: 10 << 20, AllowedTypes: imageTypes}
+
+// Spalten, über die Einträge auf Medien verweisen. Ein Medium mit
+// mindestens einem Verweis kann nicht gelöscht werden.
+var mediaReferences = []struct {
+	Table  string
+	Column string
+}{
+	{"blogs", "image_media_id"},
+	{"projects", "image_media_id"},
+	{"users", "avatar_media_id"},
+	{"languages", "icon_media_id"},
+}
+
+func addMediaCDNPrefix(media *models.Media) {
+	media.URL = withCDNPrefix(media.URL)
+	for i := range media.Variants {
+		media.Variants[i].URL = withCDNPrefix(media.Variants[i].URL)
+		media.Variants[i].WebPURL = withCDNPrefix(media.Variants[i].WebPURL)
+	}
+}
+
+// loadMediaRefCounts füllt RefCount für alle übergebenen Medien.
+func loadMediaRefCounts(mediaList []models.Media) error {
+	if len(mediaList) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(mediaList))
+	for i := range mediaList {
+		ids[i] = mediaList[i].ID
+	}
+
+	counts := map[string]int64{}
+	for _, ref := range mediaReferences {
+		var rows []struct {
+			MediaID string
+			Count   int64
+		}
+		err := database.DB.Table(ref.Table).
+			Select(ref.Column+" AS media_id, COUNT(*) AS count").
+			Where(ref.Column+" IN ?", ids).
+			Group(ref.Column).
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			counts[row.MediaID] += row.Count
+		}
+	}
+
+	for i := range mediaList {
+		mediaList[i].RefCount = counts[mediaList[i].ID]
+	}
+	return nil
+}
+
+// saveMedia legt eine geprüfte Datei in der Mediathek ab.
+func saveMedia(c *gin.Context, upload *validatedUpload, alt string) (*models.Media, error) {
+	media := models.Media{
+		Filename: filepath.Base(upload.File.Filename),
+		MimeType: upload.MimeType,
+		Size:     upload.File.Size,
+		Alt:      alt,
+	}
+	if user := middleware.CurrentUser(c); user != nil {
+		media.UploadedByID = &user.ID
+	}
+
+	tx := database.DB.Begin()
+	if err := tx.Create(&media).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	f, err := upload.File.Open()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	defer f.Close()
+
+	ctx := c.Request.Context()
+	key := "media/" + media.ID + "/original" + upload.Ext
+	if err := storage.Store.Save(ctx, key, f, upload.File.Size, upload.MimeType); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	media.URL = "/" + key
+
+	if imaging.Supported(upload.MimeType) {
+		if err := saveMediaVariants(ctx, &media, upload); err != nil {
+			tx.Rollback()
+			storage.Store.DeletePrefix(ctx, "media/"+media.ID)
+			return nil, err
+		}
+	}
+
+	if err := tx.Save(&media).Error; err != nil {
+		tx.Rollback()
+		storage.Store.DeletePrefix(ctx, "media/"+media.ID)
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		storage.Store.DeletePrefix(ctx, "media/"+media.ID)
+		return nil, err
+	}
+	return &media, nil
+}
+
+// formMedia ist ein Bild aus dem Formular: entweder ein geprüfter, aber noch
+// nicht gespeicherter Upload oder ein Medium aus der Mediathek.
+type formMedia struct {
+	upload *validatedUpload
+	media  *models.Media
+	saved  bool
+	kept   bool
+}
+
+// mediaFromForm prüft ein Upload-Feld oder ein Feld mit einer Media-ID.
+// Gespeichert wird ein Upload erst mit save, damit nach einem späteren
+// Validierungsfehler nichts übrig bleibt. Gibt es weder noch, ist form nil.
+// Bei Fehlern wurde bereits eine Antwort geschrieben und ok ist false.
+func mediaFromForm(c *gin.Context, fileField, idField string, rule uploadRule) (form *formMedia, ok bool) {
+	upload, ok := formUpload(c, fileField, rule)
+	if !ok {
+		return nil, false
+	}
+	if upload != nil {
+		return &formMedia{upload: upload}, true
+	}
+
+	mediaID := c.PostForm(idField)
+	if mediaID == "" {
+		return nil, true
+	}
+
+	media := &models.Media{}
+	if err := database.DB.First(media, "id = ?", mediaID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Field(c, idField, problem.FieldInvalid, "Media not found")
+		return nil, false
+	} else if err != nil {
+		problem.Database(c, err, "Failed to load media")
+		return nil, false
+	}
+	// Medien aus der Mediathek gelten dieselben Regeln wie Uploads
+	if _, allowed := rule.AllowedTypes[media.MimeType]; !allowed {
+		problem.Field(c, idField, problem.FieldInvalid, "Media type "+media.MimeType+" is not allowed here")
+		return nil, false
+	}
+	if media.Size > rule.MaxSize {
+		problem.Field(c, idField, problem.FieldInvalid, fmt.Sprintf("Media is too large (max %d MB)", rule.MaxSize>>20))
+		return nil, false
+	}
+	return &formMedia{media: media}, true
+}
+
+// save legt einen Upload in der Mediathek ab und liefert das Medium, bei
+// einer Media-ID das vorhandene. Danach sollte cleanup per defer folgen.
+func (m *formMedia) save(c *gin.Context) (*models.Media, bool) {
+	if m == nil {
+		return nil, true
+	}
+	if m.upload != nil && !m.saved {
+		media, err := saveMedia(c, m.upload, "")
+		if err != nil {
+			problem.Internal(c, problem.CodeStorage, err, "Failed to save file")
+			return nil, false
+		}
+		m.media, m.saved = media, true
+	}
+	return m.media, true
+}
+
+// keep wird nach dem Commit aufgerufen, das Medium bleibt dann erhalten.
+func (m *formMedia) keep() {
+	if m != nil {
+		m.kept = true
+	}
+}
+
+// cleanup löscht einen mit save gespeicherten Upload wieder, wenn der
+// Eintrag, für den er gedacht war, nicht gespeichert wurde.
+func (m *formMedia) cleanup(c *gin.Context) {
+	if m == nil || !m.saved || m.kept {
+		return
+	}
+	database.DB.Delete(m.media)
+	storage.Store.DeletePrefix(c.Request.Context(), "media/"+m.media.ID)
+}
+
+// mediaListSpec legt Filter und Sortierung für GET /media fest.
+var mediaListSpec = listSpec{
+	Table: "media",
+	Sorts: []string{"created_at", "filename", "size", "width", "height"},
+	Filters: map[string]filterField{
+		"filename":       {Column: "filename"},
+		"mime_type":      {Column: "mime_type"},
+		"uploaded_by_id": {Column: "uploaded_by_id"},
+		"size":           {Column: "size", Type: filterInt},
+		"width":          {Column: "width", Type: filterInt},
+		"height":         {Column: "height", Type: filterInt},
+		"created_at":     {Column: "created_at", Type: filterTime},
+	},
+	DefaultSort: []sortKey{{"created_at", true}},
+}
+
+func GetMediaList(c *gin.Context) {
+	mediaList := []models.Media{}
+	query := database.DB
+
+	if q := c.Query("q"); q != "" {
+		like := "%" + likeEscaper.Replace(q) + "%"
+		query = query.Where(`filename LIKE ? ESCAPE '\\' OR alt LIKE ? ESCAPE '\\'`, like, like)
+	}
+	if mimeType := c.Query("mime_type"); mimeType != "" {
+		query = query.Where("mime_type = ?", mimeType)
+	}
+	if c.Query("unused") == "true" {
+		for _, ref := range mediaReferences {
+			query = query.Where("id NOT IN (?)", database.DB.Table(ref.Table).Select(ref.Column).Where(ref.Column+" IS NOT NULL"))
+		}
+	}
+
+	query, keys, err := listQuery(c, mediaListSpec, query)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if err := paginate(c, query, keys, &mediaList); err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if err := loadMediaRefCounts(mediaList); err != nil {
+		problem.Database(c, err, "Failed to load media references")
+		return
+	}
+	for i := range mediaList {
+		addMediaCDNPrefix(&mediaList[i])
+	}
+	c.JSON(http.StatusOK, mediaList)
+}
+
+func GetMedia(c *gin.Context) {
+	var media models.Media
+	if err := database.DB.First(&media, "id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Media not found")
+		return
+	}
+
+	mediaList := []models.Media{media}
+	if err := loadMediaRefCounts(mediaList); err != nil {
+		problem.Database(c, err, "Failed to load media references")
+		return
+	}
+	media = mediaList[0]
+	addMediaCDNPrefix(&media)
+	c.JSON(http.StatusOK, media)
+}
+
+func CreateMedia(c *gin.Context) {
+	upload, ok := formUpload(c, "file", mediaUpload)
+	if !ok {
+		return
+	}
+	if upload == nil {
+		problem.Field(c, "file", problem.FieldRequired, "File is required")
+		return
+	}
+
+	media, err := saveMedia(c, upload, c.PostForm("alt"))
+	if err != nil {
+		problem.Internal(c, problem.CodeStorage, err, "Failed to save file")
+		return
+	}
+
+	addMediaCDNPrefix(media)
+	c.JSON(http.StatusCreated, media)
+}
+
+func DeleteMedia(c *gin.Context) {
+	var media models.Media
+	if err := database.DB.First(&media, "id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Media not found")
+		return
+	}
+
+	mediaList := []models.Media{media}
+	if err := loadMediaRefCounts(mediaList); err != nil {
+		problem.Database(c, err, "Failed to load media references")
+		return
+	}
+	if mediaList[0].RefCount > 0 {
+		problem.Write(c, problem.Problem{
+			Status:     http.StatusConflict,
+			Detail:     "Media is still in use",
+			Extensions: map[string]any{"ref_count": mediaList[0].RefCount},
+		})
+		return
+	}
+
+	// Prüfung und Löschen in einer Anweisung, damit niemand das Medium
+	// zwischendurch verknüpfen kann
+	query := database.DB.Where("id = ?", media.ID)
+	for _, ref := range mediaReferences {
+		query = query.Where("NOT EXISTS (?)", database.DB.Table(ref.Table).Select("1").Where(ref.Column+" = ?", media.ID))
+	}
+	result := query.Delete(&models.Media{})
+	if result.Error != nil {
+		problem.Database(c, result.Error, "Failed to delete media")
+		return
+	}
+	if result.RowsAffected == 0 {
+		problem.Abort(c, http.StatusConflict, "Media is still in use")
+		return
+	}
+
+	storage.Store.DeletePrefix(c.Request.Context(), "media/"+media.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Media deleted"})
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/problem"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const maxPageLimit = 100
+
+// sortKey ist eine Spalte der Sortierung einer Liste.
+type sortKey struct {
+	Column string
+	Desc   bool
+}
+
+// defaultPageLimit kommt aus PAGINATION_DEFAULT_LIMIT, Standard ist 50.
+func defaultPageLimit() int {
+	if value := os.Getenv("PAGINATION_DEFAULT_LIMIT"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= maxPageLimit {
+			return n
+		}
+	}
+	return 50
+}
+
+// errInvalidPage wird mit 400 beantwortet.
+var errInvalidPage = errors.New("Invalid pagination parameters")
+
+// paginate lädt eine Seite von query nach dest (Zeiger auf einen Slice) und
+// setzt die Header X-Total-Count und Link (first, prev, next).
+//
+// Unterstützt werden zwei Arten:
+//   - Offset: ?limit=20&page=3 (oder ?offset=40)
+//   - Cursor: ?limit=20&cursor=... für die nächste, ?before=... für die
+//     vorherige Seite. Die Cursor stehen in den Link-Headern.
+//
+// Die ID wird immer als letzte Sortierspalte angehängt, damit die
+// Reihenfolge eindeutig ist.
+func paginate(c *gin.Context, query *gorm.DB, keys []sortKey, dest interface{}) error {
+	stmt := &gorm.Statement{DB: database.DB}
+	if err := stmt.Parse(dest); err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+	keys = withIDKey(keys, stmt.Schema)
+
+	limit := defaultPageLimit()
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return errInvalidPage
+		}
+		limit = n
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Model(dest).Count(&total).Error; err != nil {
+		return err
+	}
+
+	cursor, before := c.Query("cursor"), c.Query("before")
+	cursorMode := cursor != "" || before != ""
+	offset := 0
+	if !cursorMode {
+		var err error
+		if offset, err = pageOffset(c, limit); err != nil {
+			return err
+		}
+	}
+
+	// Für ?before wird rückwärts sortiert und das Ergebnis danach umgedreht
+	order := keys
+	if before != "" {
+		order = make([]sortKey, len(keys))
+		for i, key := range keys {
+			order[i] = sortKey{Column: key.Column, Desc: !key.Desc}
+		}
+	}
+
+	page := query.Session(&gorm.Session{})
+	for _, key := range order {
+		page = page.Order(clauseColumn(table, key))
+	}
+	if token := cursor + before; cursorMode {
+		values, err := decodeCursor(token, stmt.Schema, keys)
+		if err != nil {
+			return errInvalidPage
+		}
+		where, args := keysetCondition(table, order, values)
+		page = page.Where(where, args...)
+	} else {
+		page = page.Offset(offset)
+	}
+
+	// Einen Eintrag mehr laden, um zu wissen, ob es weitergeht
+	if err := page.Limit(limit + 1).Find(dest).Error; err != nil {
+		return err
+	}
+
+	items := reflect.ValueOf(dest).Elem()
+	hasMore := items.Len() > limit
+	if hasMore {
+		items.Set(items.Slice(0, limit))
+	}
+	if before != "" {
+		reverseSlice(items)
+	}
+
+	links := []string{pageLink(c, map[string]string{"cursor": "", "before": "", "page": "", "offset": ""}, "first")}
+	if cursorMode {
+		// Vorwärts: next, wenn mehr kommt. Rückwärts: prev, wenn mehr kommt.
+		if items.Len() > 0 {
+			firstCursor := encodeCursor(stmt.Schema, keys, items.Index(0))
+			lastCursor := encodeCursor(stmt.Schema, keys, items.Index(items.Len()-1))
+			if before == "" || hasMore {
+				links = append(links, pageLink(c, map[string]string{"cursor": "", "before": firstCursor}, "prev"))
+			}
+			if before != "" || hasMore {
+				links = append(links, pageLink(c, map[string]string{"before": "", "cursor": lastCursor}, "next"))
+			}
+		}
+	} else {
+		pageNumber := offset/limit + 1
+		if offset > 0 {
+			links = append(links, pageLink(c, map[string]string{"offset": "", "page": strconv.Itoa(max(pageNumber-1, 1))}, "prev"))
+		}
+		if hasMore {
+			links = append(links, pageLink(c, map[string]string{"offset": "", "page": strconv.Itoa(pageNumber + 1)}, "next"))
+		}
+		if items.Len() > 0 {
+			// Cursor für den Wechsel zur Cursor-Paginierung
+			c.Header("X-Next-Cursor", encodeCursor(stmt.Schema, keys, items.Index(items.Len()-1)))
+		}
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("Link", strings.Join(links, ", "))
+	return nil
+}
+
+// respondPaginationError beantwortet einen Fehler aus paginate, listQuery oder
+// selectFields.
+func respondPaginationError(c *gin.Context, err error) {
+	if errors.Is(err, errInvalidPage) || errors.Is(err, errInvalidQuery) {
+		problem.AbortCode(c, http.StatusBadRequest, problem.CodeInvalidQuery, err.Error())
+		return
+	}
+	problem.Database(c, err, "Failed to load list")
+}
+
+func pageOffset(c *gin.Context, limit int) (int, error) {
+	if value := c.Query("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, errInvalidPage
+		}
+		return n, nil
+	}
+	if value := c.Query("page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return 0, errInvalidPage
+		}
+		return (n - 1) * limit, nil
+	}
+	return 0, nil
+}
+
+func withIDKey(keys []sortKey, s *schema.Schema) []sortKey {
+	id := s.PrioritizedPrimaryField.DBName
+	for _, key := range keys {
+		if key.Column == id {
+			return keys
+		}
+	}
+	result := append([]sortKey{}, keys...)
+	desc := len(keys) > 0 && keys[len(keys)-1].Desc
+	return append(result, sortKey{Column: id, Desc: desc})
+}
+
+func clauseColumn(table string, key sortKey) string {
+	column := table + "." + key.Column
+	if key.Desc {
+		return column + " DESC"
+	}
+	return column + " ASC"
+}
+
+// keysetCondition baut die WHERE-Bedingung "kommt nach values" für die
+// Sortierung keys. NULL steht wie bei MySQL bei ASC vorne und bei DESC hinten.
+func keysetCondition(table string, keys []sortKey, values []interface{}) (string, []interface{}) {
+	var ors []string
+	var args []interface{}
+	for i, key := range keys {
+		var ands []string
+		var clauseArgs []interface{}
+		for j := 0; j < i; j++ {
+			column := table + "." + keys[j].Column
+			if values[j] == nil {
+				ands = append(ands, column+" IS NULL")
+			} else {
+				ands = append(ands, column+" = ?")
+				clauseArgs = append(clauseArgs, values[j])
+			}
+		}
+
+		column := table + "." + key.Column
+		switch {
+		case values[i] == nil && key.Desc:
+			// Nach NULL kommt bei DESC nichts mehr
+			continue
+		case values[i] == nil:
+			ands = append(ands, column+" IS NOT NULL")
+		case key.Desc:
+			ands = append(ands, "("+column+" < ? OR "+column+" IS NULL)")
+			clauseArgs = append(clauseArgs, values[i])
+		default:
+			ands = append(ands, column+" > ?")
+			clauseArgs = append(clauseArgs, values[i])
+		}
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		args = append(args, clauseArgs...)
+	}
+
+	if len(ors) == 0 {
+		return "1 = 0", nil
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// encodeCursor verpackt die Sortierwerte eines Eintrags als URL-sicheren Text.
+func encodeCursor(s *schema.Schema, keys []sortKey, item reflect.Value) string {
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		field := s.LookUpField(key.Column)
+		if field == nil {
+			continue
+		}
+		value, zero := field.ValueOf(context.Background(), item)
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Ptr {
+			if zero || rv.IsNil() {
+				continue
+			}
+			value = rv.Elem().Interface()
+		}
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(time.RFC3339Nano)
+		}
+		values[i] = value
+	}
+	data, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor liest die Sortierwerte und wandelt sie in die Typen der Spalten.
+func decodeCursor(token string, s *schema.Schema, keys []sortKey) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	var raw []interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if len(raw) != len(keys) {
+		return nil, fmt.Errorf("cursor has %d values, expected %d", len(raw), len(keys))
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if raw[i] == nil {
+			continue
+		}
+		field := s.LookUpField(key.Column)
+		if field == nil {
+			return nil, fmt.Errorf("unknown column %s", key.Column)
+		}
+		fieldType := field.FieldType
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		switch v := raw[i].(type) {
+		case string:
+			if fieldType == reflect.TypeOf(time.Time{}) {
+				t, err := time.Parse(time.RFC3339Nano, v)
+				if err != nil {
+					return nil, err
+				}
+				values[i] = t
+			} else {
+				values[i] = v
+			}
+		case json.Number:
+			switch fieldType.Kind() {
+			case reflect.Float32, reflect.Float64:
+				values[i], err = v.Float64()
+			default:
+				values[i], err = v.Int64()
+			}
+			if err != nil {
+				return nil, err
+			}
+		case bool:
+			values[i] = v
+		default:
+			return nil, fmt.Errorf("invalid cursor value")
+		}
+	}
+	return values, nil
+}
+
+// pageLink baut einen Link-Header-Eintrag zur aktuellen URL mit geänderten
+// Query-Parametern. Leere Werte entfernen den Parameter.
+func pageLink(c *gin.Context, params map[string]string, rel string) string {
+	query := c.Request.URL.Query()
+	for key, value := range params {
+		if value == "" {
+			query.Del(key)
+		} else {
+			query.Set(key, value)
+		}
+	}
+	link := c.Request.URL.Path
+	if encoded := query.Encode(); encoded != "" {
+		link += "?" + encoded
+	}
+	return "<" + link + `>; rel="` + rel + `"`
+}
+
+func reverseSlice(items reflect.Value) {
+	swap := reflect.Swapper(items.Interface())
+	for i, j := 0, items.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
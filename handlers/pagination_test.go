@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"PortfolioAPI/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func blogSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+	s, err := schema.Parse(&models.Blog{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	return s
+}
+
+func testContext(target string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return c
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	s := blogSchema(t)
+	published := time.Date(2024, 5, 1, 12, 30, 0, 123, time.UTC)
+	keys := []sortKey{{"pinned", true}, {"published_at", true}, {"title", false}, {"word_count", false}, {"id", true}}
+
+	tests := []struct {
+		name string
+		blog models.Blog
+		want []interface{}
+	}{
+		{
+			name: "all values",
+			blog: models.Blog{ID: 7, Pinned: true, PublishedAt: &published, Title: "Go", WordCount: 120},
+			want: []interface{}{true, published, "Go", int64(120), int64(7)},
+		},
+		{
+			name: "nil pointer",
+			blog: models.Blog{ID: 3, Title: "Draft"},
+			want: []interface{}{false, nil, "Draft", int64(0), int64(3)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := encodeCursor(s, keys, reflect.ValueOf(&tt.blog).Elem())
+			got, err := decodeCursor(token, s, keys)
+			if err != nil {
+				t.Fatalf("decodeCursor: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d values, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if wt, ok := tt.want[i].(time.Time); ok {
+					if gt, ok := got[i].(time.Time); !ok || !gt.Equal(wt) {
+						t.Errorf("value %d = %v, want %v", i, got[i], wt)
+					}
+					continue
+				}
+				if got[i] != tt.want[i] {
+					t.Errorf("value %d = %#v, want %#v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	s := blogSchema(t)
+	keys := []sortKey{{"published_at", true}, {"id", true}}
+	encode := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "!!!"},
+		{"not json", encode("not json")},
+		{"not a list", encode(`{"id":1}`)},
+		{"too few values", encode(`[1]`)},
+		{"too many values", encode(`["2024-01-01T00:00:00Z",1,2]`)},
+		{"invalid time", encode(`["yesterday",1]`)},
+		{"object value", encode(`[{"a":1},1]`)},
+		{"float for int column", encode(`[null,1.5]`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.token, s, keys); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	unknown := []sortKey{{"missing", false}}
+	if _, err := decodeCursor(encode(`["x"]`), s, unknown); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		keys   []sortKey
+		values []interface{}
+		where  string
+		args   []interface{}
+	}{
+		{
+			name:   "ascending",
+			keys:   []sortKey{{"id", false}},
+			values: []interface{}{int64(5)},
+			where:  "((blogs.id > ?))",
+			args:   []interface{}{int64(5)},
+		},
+		{
+			name:   "descending includes null",
+			keys:   []sortKey{{"published_at", true}},
+			values: []interface{}{at},
+			where:  "(((blogs.published_at < ? OR blogs.published_at IS NULL)))",
+			args:   []interface{}{at},
+		},
+		{
+			name:   "null ascending",
+			keys:   []sortKey{{"published_at", false}},
+			values: []interface{}{nil},
+			where:  "((blogs.published_at IS NOT NULL))",
+		},
+		{
+			name:   "null descending has nothing after it",
+			keys:   []sortKey{{"published_at", true}},
+			values: []interface{}{nil},
+			where:  "1 = 0",
+		},
+		{
+			name:   "tie breaker",
+			keys:   []sortKey{{"title", false}, {"id", true}},
+			values: []interface{}{"Go", int64(3)},
+			where:  "((blogs.title > ?) OR (blogs.title = ? AND (blogs.id < ? OR blogs.id IS NULL)))",
+			args:   []interface{}{"Go", "Go", int64(3)},
+		},
+		{
+			name:   "null before tie breaker",
+			keys:   []sortKey{{"published_at", true}, {"id", true}},
+			values: []interface{}{nil, int64(3)},
+			where:  "((blogs.published_at IS NULL AND (blogs.id < ? OR blogs.id IS NULL)))",
+			args:   []interface{}{int64(3)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := keysetCondition("blogs", tt.keys, tt.values)
+			if where != tt.where {
+				t.Errorf("where = %q, want %q", where, tt.where)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %v, want %v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestWithIDKey(t *testing.T) {
+	s := blogSchema(t)
+	tests := []struct {
+		name string
+		keys []sortKey
+		want []sortKey
+	}{
+		{"empty", nil, []sortKey{{"id", false}}},
+		{"follows last direction", []sortKey{{"created_at", true}}, []sortKey{{"created_at", true}, {"id", true}}},
+		{"already present", []sortKey{{"id", false}, {"title", true}}, []sortKey{{"id", false}, {"title", true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withIDKey(tt.keys, s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withIDKey = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageOffset(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    int
+		invalid bool
+	}{
+		{"", 0, false},
+		{"page=1", 0, false},
+		{"page=3", 40, false},
+		{"offset=15", 15, false},
+		{"offset=15&page=3", 15, false},
+		{"page=0", 0, true},
+		{"page=abc", 0, true},
+		{"offset=-1", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := pageOffset(testContext("/blogs?"+tt.query), 20)
+			if tt.invalid {
+				if !errors.Is(err, errInvalidPage) {
+					t.Errorf("err = %v, want errInvalidPage", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("pageOffset = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageLink(t *testing.T) {
+	c := testContext("/blogs?limit=10&page=2&sort=-title")
+	got := pageLink(c, map[string]string{"page": "", "cursor": "abc"}, "next")
+	want := `</blogs?cursor=abc&limit=10&sort=-title>; rel="next"`
+	if got != want {
+		t.Errorf("pageLink = %s, want %s", got, want)
+	}
+}
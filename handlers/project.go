@@ -1,20 +1,22 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"PortfolioAPI/database"
 	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
 func addProjectCDNPrefix(project *models.Project) {
+	if project.ImageMedia != nil {
+		project.Images = imageSetFor(project.ImageMedia)
+	}
 	if project.Image != "" && !strings.HasPrefix(project.Image, "http") {
 		project.Image = getCDNURL() + project.Image
 	}
@@ -30,23 +32,82 @@ func addProjectCDNPrefix(project *models.Project) {
 	}
 }
 
+// projectListSpec legt Filter und Sortierung für GET /projects fest.
+var projectListSpec = listSpec{
+	Table: "projects",
+	Sorts: []string{"created_at", "updated_at", "title"},
+	Filters: map[string]filterField{
+		"title":       {Column: "title"},
+		"link":        {Column: "link"},
+		"created_at":  {Column: "created_at", Type: filterTime},
+		"updated_at":  {Column: "updated_at", Type: filterTime},
+		"author_id":   {Column: "user_id", Through: "project_authors", Key: "project_id"},
+		"language_id": {Column: "language_id", Through: "project_languages", Key: "project_id"},
+	},
+	DefaultSort: []sortKey{{"created_at", true}},
+}
+
+// projectFieldSpec legt ?fields= und ?include= für Projects fest.
+var projectFieldSpec = fieldSpec{
+	Table:  "projects",
+	Fields: []string{"id", "title", "description", "image", "image_media_id", "link", "created_at", "updated_at"},
+	Computed: map[string]computedField{
+		"images": {Columns: []string{"image_media_id"}, Preload: "ImageMedia"},
+	},
+	Includes: map[string]includeFunc{
+		"languages": preloadInclude("Languages"),
+		"authors":   preloadInclude("Authors"),
+		"tags":      preloadInclude("Tags"),
+	},
+	DefaultIncludes: []string{"languages", "authors", "tags"},
+}
+
 func GetProjects(c *gin.Context) {
 	projects := []models.Project{}
-	database.DB.Preload("Languages").Preload("Authors").Find(&projects)
+	query := database.DB.Scopes(taggedWith(c, "projects", "project_tags", "project_id"))
+	query, keys, err := listQuery(c, projectListSpec, query)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	query, keep, err := selectFields(c, projectFieldSpec, query, keys)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if err := paginate(c, query, keys, &projects); err != nil {
+		respondPaginationError(c, err)
+		return
+	}
 	for i := range projects {
 		addProjectCDNPrefix(&projects[i])
 	}
-	c.JSON(http.StatusOK, projects)
+	result, err := sparseJSON(projects, keep)
+	if err != nil {
+		problem.Internal(c, problem.CodeInternal, err, "Failed to encode response")
+		return
+	}
+	c.JSON(http.StatusOK, result)
 }
 
 func GetProject(c *gin.Context) {
 	var project models.Project
-	if err := database.DB.Preload("Languages").Preload("Authors").First(&project, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	query, keep, err := selectFields(c, projectFieldSpec, database.DB, nil)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if err := query.First(&project, "projects.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Project not found")
 		return
 	}
 	addProjectCDNPrefix(&project)
-	c.JSON(http.StatusOK, project)
+	result, err := sparseJSON(project, keep)
+	if err != nil {
+		problem.Internal(c, problem.CodeInternal, err, "Failed to encode project")
+		return
+	}
+	c.JSON(http.StatusOK, result)
 }
 
 func CreateProject(c *gin.Context) {
@@ -56,10 +117,27 @@ func CreateProject(c *gin.Context) {
 	link := c.PostForm("link")
 	createdAtStr := c.PostForm("created_at")
 	languageIDsStr := c.PostForm("language_ids")
-	authorIDsStr := c.PostForm("author_ids")
+	authorIDsStr := withCurrentAuthor(c, c.PostForm("author_ids"))
+
+	var missing []problem.FieldError
+	if title == "" {
+		missing = append(missing, problem.FieldError{Field: "title", Code: problem.FieldRequired, Message: "Title is required"})
+	}
+	if description == "" {
+		missing = append(missing, problem.FieldError{Field: "description", Code: problem.FieldRequired, Message: "Description is required"})
+	}
+	if len(missing) > 0 {
+		problem.Invalid(c, missing...)
+		return
+	}
+	tagNames, ok := formTags(c, c.PostForm("tags"))
+	if !ok {
+		return
+	}
 
-	if title == "" || description == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Title and description are required"})
+	// Bild hochgeladen oder aus der Mediathek gewählt?
+	image, ok := mediaFromForm(c, "image_file", "image_media_id", projectImageUpload)
+	if !ok {
 		return
 	}
 
@@ -79,46 +157,59 @@ func CreateProject(c *gin.Context) {
 	}
 
 	if languageIDsStr != "" {
-		languageIDs := strings.Split(languageIDsStr, ",")
-		var languages []models.Language
-		if err := database.DB.Where("id IN ?", languageIDs).Find(&languages).Error; err == nil && len(languages) > 0 {
-			project.Languages = languages
+		if err := database.DB.Where("id IN ?", strings.Split(languageIDsStr, ",")).Find(&project.Languages).Error; err != nil {
+			problem.Database(c, err, "Failed to load languages")
+			return
 		}
 	}
 
 	if authorIDsStr != "" {
-		authorIDs := strings.Split(authorIDsStr, ",")
-		var authors []models.User
-		if err := database.DB.Where("id IN ?", authorIDs).Find(&authors).Error; err == nil && len(authors) > 0 {
-			project.Authors = authors
+		if err := database.DB.Where("id IN ?", strings.Split(authorIDsStr, ",")).Find(&project.Authors).Error; err != nil {
+			problem.Database(c, err, "Failed to load authors")
+			return
 		}
 	}
 
-	database.DB.Create(&project)
-
-	// Bild hochgeladen?
-	file, err := c.FormFile("image_file")
-	if err == nil {
-		projectDir := filepath.Join("public", "projects", project.ID)
-		if err := os.MkdirAll(projectDir, 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project directory"})
-			return
-		}
+	// Upload erst speichern, wenn alles andere gültig ist
+	media, ok := image.save(c)
+	if !ok {
+		return
+	}
+	defer image.cleanup(c)
+	if media != nil {
+		project.Image = media.URL
+		project.ImageMediaID = &media.ID
+	}
 
-		ext := filepath.Ext(file.Filename)
-		filename := fmt.Sprintf("image%s", ext)
-		filePath := filepath.Join(projectDir, filename)
+	// Transaktion: neue Tags nur anlegen, wenn auch das Project gespeichert wird
+	tx := database.DB.Begin()
 
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
+	// Tags nach Namen zuordnen, neue werden angelegt
+	if len(tagNames) > 0 {
+		tags, err := tagsByName(tx, tagNames)
+		if err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to save tags")
 			return
 		}
+		project.Tags = tags
+	}
 
-		project.Image = fmt.Sprintf("/projects/%s/%s", project.ID, filename)
-		database.DB.Save(&project)
+	if err := tx.Create(&project).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to create project")
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to create project")
+		return
 	}
+	image.keep()
 
-	database.DB.Preload("Languages").Preload("Authors").First(&project, "id = ?", project.ID)
+	if err := database.DB.Preload("Languages").Preload("Authors").Preload("Tags").Preload("ImageMedia").First(&project, "id = ?", project.ID).Error; err != nil {
+		problem.Database(c, err, "Failed to load project")
+		return
+	}
 	addProjectCDNPrefix(&project)
 	c.JSON(http.StatusCreated, project)
 }
@@ -126,7 +217,7 @@ func CreateProject(c *gin.Context) {
 func UpdateProject(c *gin.Context) {
 	var project models.Project
 	if err := database.DB.First(&project, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		problem.Lookup(c, err, "Project not found")
 		return
 	}
 
@@ -146,6 +237,7 @@ func UpdateProject(c *gin.Context) {
 	}
 	if imageURL != "" {
 		project.Image = imageURL
+		project.ImageMediaID = nil
 	}
 	if link != "" {
 		project.Link = link
@@ -157,52 +249,88 @@ func UpdateProject(c *gin.Context) {
 			project.CreatedAt = t
 		}
 	}
+	tagsStr, hasTags := c.GetPostForm("tags")
+	tagNames, ok := formTags(c, tagsStr)
+	if !ok {
+		return
+	}
 
-	// Bild hochgeladen?
-	file, err := c.FormFile("image_file")
-	if err == nil {
-		projectDir := filepath.Join("public", "projects", project.ID)
-		if err := os.MkdirAll(projectDir, 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project directory"})
-			return
-		}
-
-		// Alte Bilder löschen
-		matches, _ := filepath.Glob(filepath.Join(projectDir, "image.*"))
-		for _, match := range matches {
-			os.Remove(match)
-		}
-
-		ext := filepath.Ext(file.Filename)
-		filename := fmt.Sprintf("image%s", ext)
-		filePath := filepath.Join(projectDir, filename)
-
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
-			return
-		}
-
-		project.Image = fmt.Sprintf("/projects/%s/%s", project.ID, filename)
+	// Bild hochgeladen oder aus der Mediathek gewählt?
+	image, ok := mediaFromForm(c, "image_file", "image_media_id", projectImageUpload)
+	if !ok {
+		return
+	}
+	media, ok := image.save(c)
+	if !ok {
+		return
 	}
+	defer image.cleanup(c)
+	if media != nil {
+		project.Image = media.URL
+		project.ImageMediaID = &media.ID
+	}
+
+	// Transaktion: Project und Verknüpfungen ganz oder gar nicht speichern
+	tx := database.DB.Begin()
 
 	// Language IDs verarbeiten
 	if languageIDsStr != "" {
-		languageIDs := strings.Split(languageIDsStr, ",")
 		var languages []models.Language
-		database.DB.Where("id IN ?", languageIDs).Find(&languages)
-		database.DB.Model(&project).Association("Languages").Replace(languages)
+		if err := tx.Where("id IN ?", strings.Split(languageIDsStr, ",")).Find(&languages).Error; err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to load languages")
+			return
+		}
+		if err := tx.Model(&project).Association("Languages").Replace(languages); err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to save languages")
+			return
+		}
 	}
 
 	// Author IDs verarbeiten
 	if authorIDsStr != "" {
-		authorIDs := strings.Split(authorIDsStr, ",")
 		var authors []models.User
-		database.DB.Where("id IN ?", authorIDs).Find(&authors)
-		database.DB.Model(&project).Association("Authors").Replace(authors)
+		if err := tx.Where("id IN ?", strings.Split(authorIDsStr, ",")).Find(&authors).Error; err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to load authors")
+			return
+		}
+		if err := tx.Model(&project).Association("Authors").Replace(authors); err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to save authors")
+			return
+		}
+	}
+
+	// Tags verarbeiten, ein leeres Feld entfernt alle Tags
+	if hasTags {
+		tags, err := tagsByName(tx, tagNames)
+		if err == nil {
+			err = tx.Model(&project).Association("Tags").Replace(tags)
+		}
+		if err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to save tags")
+			return
+		}
+	}
+
+	if err := tx.Save(&project).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to update project")
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to update project")
+		return
 	}
+	image.keep()
 
-	database.DB.Save(&project)
-	database.DB.Preload("Languages").Preload("Authors").First(&project, "id = ?", project.ID)
+	if err := database.DB.Preload("Languages").Preload("Authors").Preload("Tags").Preload("ImageMedia").First(&project, "id = ?", project.ID).Error; err != nil {
+		problem.Database(c, err, "Failed to load project")
+		return
+	}
 	addProjectCDNPrefix(&project)
 	c.JSON(http.StatusOK, project)
 }
@@ -210,7 +338,7 @@ func UpdateProject(c *gin.Context) {
 func DeleteProject(c *gin.Context) {
 	var project models.Project
 	if err := database.DB.First(&project, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		problem.Lookup(c, err, "Project not found")
 		return
 	}
 
@@ -221,26 +349,35 @@ func DeleteProject(c *gin.Context) {
 
 	if err := tx.Exec("DELETE FROM project_languages WHERE project_id = ?", projectID).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project languages"})
+		problem.Database(c, err, "Failed to delete project languages")
 		return
 	}
 
 	if err := tx.Exec("DELETE FROM project_authors WHERE project_id = ?", projectID).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project authors"})
+		problem.Database(c, err, "Failed to delete project authors")
+		return
+	}
+
+	if err := tx.Exec("DELETE FROM project_tags WHERE project_id = ?", projectID).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to delete project tags")
 		return
 	}
 
 	if err := tx.Delete(&project).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
+		problem.Database(c, err, "Failed to delete project")
 		return
 	}
 
-	tx.Commit()
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to delete project")
+		return
+	}
 
-	// Project-Bilder Ordner löschen
-	os.RemoveAll(filepath.Join("public", "projects", projectID))
+	// Alten Project-Bilder Ordner löschen (Uploads vor der Mediathek)
+	storage.Store.DeletePrefix(c.Request.Context(), "projects/"+projectID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Project deleted"})
 }
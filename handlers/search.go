@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/markdown"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	searchSnippetLength = 200
+	maxSearchLimit      = 50
+)
+
+type searchResult struct {
+	Type      string  `json:"type"`
+	ID        any     `json:"id"`
+	Title     string  `json:"title"`
+	TitleHTML string  `json:"title_html"`
+	Slug      string  `json:"slug,omitempty"`
+	Snippet   string  `json:"snippet"`
+	Image     string  `json:"image,omitempty"`
+	Score     float64 `json:"score"`
+}
+
+// fulltextHits sucht mit MATCH ... AGAINST in einer Tabelle. Die Spalten
+// entsprechen den Indizes aus database.fulltextIndexes. Die Scores hängen von
+// Tabelle und Spalten ab und werden deshalb auf den besten Treffer der
+// Tabelle normiert (0 bis 1), damit Blogs und Projects vergleichbar sind.
+func fulltextHits(c *gin.Context, docType, q string, limit int) ([]search.Hit, error) {
+	table, columns := "blogs", "blogs.title, blogs.excerpt, blogs.content"
+	if docType == "project" {
+		table, columns = "projects", "projects.title, projects.description"
+	}
+	match := "MATCH(" + columns + ") AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+	var rows []struct {
+		ID    string
+		Score float64
+	}
+	query := database.DB.Table(table).
+		Select(table+".id AS id, "+match+" AS score", q).
+		Where(match, q)
+	if docType == "blog" {
+		query = query.Scopes(visibleBlogs(c))
+	}
+	if err := query.Order("score DESC").Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	hits := make([]search.Hit, len(rows))
+	for i, row := range rows {
+		score := row.Score
+		if rows[0].Score > 0 {
+			score /= rows[0].Score
+		}
+		hits[i] = search.Hit{Type: docType, ID: row.ID, Score: score}
+	}
+	return hits, nil
+}
+
+// Search durchsucht Blogs und Projects über die FULLTEXT-Indizes. Mit
+// ?type=blog oder ?type=project nur eins davon.
+func Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		problem.AbortCode(c, http.StatusBadRequest, problem.CodeInvalidQuery, "q is required")
+		return
+	}
+	docType := c.Query("type")
+	if docType != "" && docType != "blog" && docType != "project" {
+		problem.AbortCode(c, http.StatusBadRequest, problem.CodeInvalidQuery, "type must be blog or project")
+		return
+	}
+	limit := 20
+	if value := c.Query("limit"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= maxSearchLimit {
+			limit = n
+		}
+	}
+
+	var hits []search.Hit
+	for _, t := range []string{"blog", "project"} {
+		if docType != "" && docType != t {
+			continue
+		}
+		typeHits, err := fulltextHits(c, t, q, limit)
+		if err != nil {
+			problem.Database(c, err, "Search failed")
+			return
+		}
+		hits = append(hits, typeHits...)
+	}
+
+	results, err := searchResults(c, hits, q)
+	if err != nil {
+		problem.Database(c, err, "Failed to load search results")
+		return
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":   q,
+		"results": results,
+	})
+}
+
+// searchResults lädt die Treffer, sortiert nach Relevanz, und erzeugt die
+// Snippets. Blogs, die der Aufrufer nicht sehen darf, fallen heraus.
+func searchResults(c *gin.Context, hits []search.Hit, q string) ([]searchResult, error) {
+	var blogIDs, projectIDs []string
+	for _, hit := range hits {
+		if hit.Type == "blog" {
+			blogIDs = append(blogIDs, hit.ID)
+		} else {
+			projectIDs = append(projectIDs, hit.ID)
+		}
+	}
+
+	blogs := map[string]models.Blog{}
+	if len(blogIDs) > 0 {
+		var list []models.Blog
+		if err := database.DB.Scopes(visibleBlogs(c)).Where("blogs.id IN ?", blogIDs).Find(&list).Error; err != nil {
+			return nil, err
+		}
+		for _, blog := range list {
+			blogs[strconv.Itoa(int(blog.ID))] = blog
+		}
+	}
+	projects := map[string]models.Project{}
+	if len(projectIDs) > 0 {
+		var list []models.Project
+		if err := database.DB.Where("id IN ?", projectIDs).Find(&list).Error; err != nil {
+			return nil, err
+		}
+		for _, project := range list {
+			projects[project.ID] = project
+		}
+	}
+
+	results := []searchResult{}
+	for _, hit := range hits {
+		switch hit.Type {
+		case "blog":
+			blog, ok := blogs[hit.ID]
+			if !ok {
+				continue
+			}
+			results = append(results, searchResult{
+				Type:      "blog",
+				ID:        blog.ID,
+				Title:     blog.Title,
+				TitleHTML: search.Highlight(blog.Title, q),
+				Slug:      blog.Slug,
+				Snippet:   search.Snippet(blog.Excerpt+"\n"+markdown.PlainText(blog.Content), q, searchSnippetLength),
+				Image:     withCDNPrefix(blog.Image),
+				Score:     hit.Score,
+			})
+		case "project":
+			project, ok := projects[hit.ID]
+			if !ok {
+				continue
+			}
+			results = append(results, searchResult{
+				Type:      "project",
+				ID:        project.ID,
+				Title:     project.Title,
+				TitleHTML: search.Highlight(project.Title, q),
+				Snippet:   search.Snippet(project.Description, q, searchSnippetLength),
+				Image:     withCDNPrefix(project.Image),
+				Score:     hit.Score,
+			})
+		}
+	}
+
+	// Bei MySQL kommen die Treffer pro Tabelle mit normierten Scores, daher
+	// gemeinsam sortieren
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results, nil
+}
@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/slug"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// seriesParts liefert die sichtbaren Teile einer Serie in Reihenfolge.
+func seriesParts(c *gin.Context, seriesID string) ([]models.SeriesPart, error) {
+	parts, err := seriesPartsFor(c, []string{seriesID})
+	if err != nil {
+		return nil, err
+	}
+	if parts[seriesID] == nil {
+		return []models.SeriesPart{}, nil
+	}
+	return parts[seriesID], nil
+}
+
+// seriesPartsFor lädt die sichtbaren Teile mehrerer Serien mit einer Abfrage.
+func seriesPartsFor(c *gin.Context, seriesIDs []string) (map[string][]models.SeriesPart, error) {
+	parts := map[string][]models.SeriesPart{}
+	if len(seriesIDs) == 0 {
+		return parts, nil
+	}
+
+	var blogs []models.Blog
+	err := database.DB.Select("id, title, slug, series_id, series_position").
+		Scopes(visibleBlogs(c)).
+		Where("series_id IN ?", seriesIDs).
+		Order("series_position ASC, id ASC").
+		Find(&blogs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, blog := range blogs {
+		seriesID := *blog.SeriesID
+		parts[seriesID] = append(parts[seriesID], models.SeriesPart{
+			ID:       blog.ID,
+			Title:    blog.Title,
+			Slug:     blog.Slug,
+			Position: len(parts[seriesID]) + 1,
+		})
+	}
+	return parts, nil
+}
+
+// attachSeries füllt Blog.Series mit den Metadaten der Serie sowie dem
+// vorherigen und nächsten Teil.
+func attachSeries(c *gin.Context, blog *models.Blog) error {
+	if blog.SeriesID == nil {
+		return nil
+	}
+
+	var series models.Series
+	if err := database.DB.First(&series, "id = ?", *blog.SeriesID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	parts, err := seriesParts(c, series.ID)
+	if err != nil {
+		return err
+	}
+
+	info := &models.SeriesInfo{
+		ID:          series.ID,
+		Title:       series.Title,
+		Slug:        series.Slug,
+		Description: series.Description,
+		Total:       len(parts),
+		Parts:       parts,
+	}
+	for i, part := range parts {
+		if part.ID != blog.ID {
+			continue
+		}
+		info.Position = part.Position
+		if i > 0 {
+			info.Previous = &parts[i-1]
+		}
+		if i < len(parts)-1 {
+			info.Next = &parts[i+1]
+		}
+	}
+	blog.Series = info
+	return nil
+}
+
+func uniqueSeriesSlug(title, seriesID string) (string, error) {
+	base := slug.Make(title)
+	if base == "" {
+		base = "series"
+	}
+	s := base
+	for i := 2; ; i++ {
+		taken, err := seriesSlugTaken(s, seriesID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return s, nil
+		}
+		s = base + "-" + strconv.Itoa(i)
+	}
+}
+
+func seriesSlugTaken(s, seriesID string) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.Series{}).Where("slug = ? AND id <> ?", s, seriesID).Count(&count).Error
+	return count > 0, err
+}
+
+// checkSeriesSlug normalisiert einen vom Client gesendeten Slug und prüft,
+// ob er frei ist. Bei einem Fehler ist die Antwort schon geschrieben.
+func checkSeriesSlug(c *gin.Context, value, seriesID string) (string, bool) {
+	s := slug.Make(value)
+	if s == "" {
+		problem.Field(c, "slug", problem.FieldInvalid, "Slug is invalid")
+		return "", false
+	}
+	taken, err := seriesSlugTaken(s, seriesID)
+	if err != nil {
+		problem.Database(c, err, "Failed to check slug")
+		return "", false
+	}
+	if taken {
+		problem.Taken(c, "slug", "Slug is already in use")
+		return "", false
+	}
+	return s, true
+}
+
+// setSeriesBlogs ersetzt die Teile einer Serie. Die Reihenfolge der IDs ist
+// die Reihenfolge der Teile. Blogs aus einer anderen Serie wechseln hierher.
+func setSeriesBlogs(tx *gorm.DB, seriesID string, blogIDs []string) error {
+	if err := tx.Model(&models.Blog{}).Where("series_id = ?", seriesID).
+		Updates(map[string]interface{}{"series_id": nil, "series_position": 0}).Error; err != nil {
+		return err
+	}
+	for i, id := range blogIDs {
+		if err := tx.Model(&models.Blog{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"series_id": seriesID, "series_position": i + 1}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSeriesBlogIDs prüft, ob alle IDs gültig und eindeutig sind und die
+// Blogs existieren. Entwürfe und archivierte Blogs werden abgelehnt, da sie
+// Lesern fehlen würden und die Nummerierung der Teile verschieben. Geplante
+// Blogs sind erlaubt, sie erscheinen mit ihrer Veröffentlichung.
+func parseSeriesBlogIDs(value string) ([]string, *problem.FieldError, error) {
+	ids := []string{}
+	seen := map[uint64]bool{}
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(id, 10, 0)
+		if err != nil {
+			return nil, &problem.FieldError{Field: "blog_ids", Code: problem.FieldInvalid,
+				Message: "Invalid blog id " + strconv.Quote(id)}, nil
+		}
+		if seen[n] {
+			return nil, &problem.FieldError{Field: "blog_ids", Code: problem.FieldInvalid,
+				Message: "Blog " + strconv.FormatUint(n, 10) + " is listed more than once"}, nil
+		}
+		seen[n] = true
+		ids = append(ids, strconv.FormatUint(n, 10))
+	}
+	if len(ids) == 0 {
+		return ids, nil, nil
+	}
+
+	var blogs []models.Blog
+	if err := database.DB.Select("id, status").Where("id IN ?", ids).Find(&blogs).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(blogs) != len(ids) {
+		return nil, &problem.FieldError{Field: "blog_ids", Code: problem.FieldInvalid, Message: "Blogs not found"}, nil
+	}
+	for _, blog := range blogs {
+		if blog.Status != models.BlogStatusPublished && blog.Status != models.BlogStatusScheduled {
+			return nil, &problem.FieldError{Field: "blog_ids", Code: problem.FieldInvalid,
+				Message: "Blog " + strconv.Itoa(int(blog.ID)) + " is not published or scheduled"}, nil
+		}
+	}
+	return ids, nil, nil
+}
+
+func findSeries(c *gin.Context) (*models.Series, bool) {
+	var series models.Series
+	if err := database.DB.First(&series, "id = ? OR slug = ?", c.Param("id"), c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Series not found")
+		return nil, false
+	}
+	return &series, true
+}
+
+func GetSeriesList(c *gin.Context) {
+	seriesList := []models.Series{}
+	if err := database.DB.Order("title ASC").Find(&seriesList).Error; err != nil {
+		problem.Database(c, err, "Failed to load series")
+		return
+	}
+
+	seriesIDs := make([]string, len(seriesList))
+	for i, series := range seriesList {
+		seriesIDs[i] = series.ID
+	}
+	partsBySeries, err := seriesPartsFor(c, seriesIDs)
+	if err != nil {
+		problem.Database(c, err, "Failed to load series parts")
+		return
+	}
+
+	result := make([]gin.H, len(seriesList))
+	for i, series := range seriesList {
+		parts := partsBySeries[series.ID]
+		if parts == nil {
+			parts = []models.SeriesPart{}
+		}
+		result[i] = gin.H{
+			"id":          series.ID,
+			"title":       series.Title,
+			"slug":        series.Slug,
+			"description": series.Description,
+			"total":       len(parts),
+			"parts":       parts,
+			"created_at":  series.CreatedAt,
+			"updated_at":  series.UpdatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// loadSeriesBlogs lädt die sichtbaren Teile einer Serie in Reihenfolge.
+func loadSeriesBlogs(c *gin.Context, series *models.Series) error {
+	err := database.DB.Preload("Blogs", func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(visibleBlogs(c)).Order("series_position ASC, id ASC")
+	}).Preload("Blogs.Authors").Preload("Blogs.ImageMedia").First(series, "id = ?", series.ID).Error
+	if err != nil {
+		return err
+	}
+	for i := range series.Blogs {
+		addBlogCDNPrefix(&series.Blogs[i])
+	}
+	return nil
+}
+
+// GetSeries findet eine Serie über ID oder Slug, samt aller sichtbaren Teile.
+func GetSeries(c *gin.Context) {
+	series, ok := findSeries(c)
+	if !ok {
+		return
+	}
+	if err := loadSeriesBlogs(c, series); err != nil {
+		problem.Database(c, err, "Failed to load series parts")
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+func CreateSeries(c *gin.Context) {
+	title := c.PostForm("title")
+	seriesSlug := c.PostForm("slug")
+	if title == "" {
+		problem.Field(c, "title", problem.FieldRequired, "Title is required")
+		return
+	}
+
+	series := models.Series{
+		Title:       title,
+		Description: c.PostForm("description"),
+	}
+	if seriesSlug == "" {
+		generated, err := uniqueSeriesSlug(title, "")
+		if err != nil {
+			problem.Database(c, err, "Failed to generate slug")
+			return
+		}
+		series.Slug = generated
+	} else {
+		normalized, ok := checkSeriesSlug(c, seriesSlug, "")
+		if !ok {
+			return
+		}
+		series.Slug = normalized
+	}
+
+	blogIDs, fieldErr, err := parseSeriesBlogIDs(c.PostForm("blog_ids"))
+	if err != nil {
+		problem.Database(c, err, "Failed to load blogs")
+		return
+	}
+	if fieldErr != nil {
+		problem.Invalid(c, *fieldErr)
+		return
+	}
+
+	tx := database.DB.Begin()
+	if err := tx.Create(&series).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to create series")
+		return
+	}
+	if err := setSeriesBlogs(tx, series.ID, blogIDs); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to save series parts")
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to create series")
+		return
+	}
+
+	if err := loadSeriesBlogs(c, &series); err != nil {
+		problem.Database(c, err, "Failed to load series parts")
+		return
+	}
+	c.JSON(http.StatusCreated, series)
+}
+
+func UpdateSeries(c *gin.Context) {
+	series, ok := findSeries(c)
+	if !ok {
+		return
+	}
+
+	if title := c.PostForm("title"); title != "" {
+		series.Title = title
+	}
+	if seriesSlug := c.PostForm("slug"); seriesSlug != "" {
+		normalized, ok := checkSeriesSlug(c, seriesSlug, series.ID)
+		if !ok {
+			return
+		}
+		series.Slug = normalized
+	}
+	if description, ok := c.GetPostForm("description"); ok {
+		series.Description = description
+	}
+
+	tx := database.DB.Begin()
+	if err := tx.Save(series).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to update series")
+		return
+	}
+
+	// Reihenfolge der Teile: blog_ids in gewünschter Reihenfolge
+	if blogIDsStr, ok := c.GetPostForm("blog_ids"); ok {
+		blogIDs, fieldErr, err := parseSeriesBlogIDs(blogIDsStr)
+		if err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to load blogs")
+			return
+		}
+		if fieldErr != nil {
+			tx.Rollback()
+			problem.Invalid(c, *fieldErr)
+			return
+		}
+		if err := setSeriesBlogs(tx, series.ID, blogIDs); err != nil {
+			tx.Rollback()
+			problem.Database(c, err, "Failed to save series parts")
+			return
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to update series")
+		return
+	}
+
+	if err := loadSeriesBlogs(c, series); err != nil {
+		problem.Database(c, err, "Failed to load series parts")
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+func DeleteSeries(c *gin.Context) {
+	series, ok := findSeries(c)
+	if !ok {
+		return
+	}
+
+	tx := database.DB.Begin()
+
+	// Die Blogs bleiben erhalten, nur ohne Serie
+	if err := setSeriesBlogs(tx, series.ID, nil); err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to remove blogs from series")
+		return
+	}
+
+	if err := tx.Delete(series).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to delete series")
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to delete series")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Series deleted"})
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/slug"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// splitTags teilt eine kommagetrennte Liste von Tag-Namen. Namen mit
+// gleichem Slug ("Go" und "go") zählen nur einmal.
+func splitTags(value string) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		s := slug.Make(name)
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// Länge der Spalten name und slug
+const maxTagLength = 100
+
+// formTags liest die Tag-Namen aus einem Formularfeld. Zu lange Namen werden
+// mit einem Feldfehler abgelehnt, die Antwort ist dann schon geschrieben.
+func formTags(c *gin.Context, value string) ([]string, bool) {
+	names := splitTags(value)
+	for _, name := range names {
+		if utf8.RuneCountInString(name) > maxTagLength || len(slug.Make(name)) > maxTagLength {
+			problem.Field(c, "tags", problem.FieldInvalid,
+				fmt.Sprintf("Tag %q is too long (max %d characters)", name, maxTagLength))
+			return nil, false
+		}
+	}
+	return names, true
+}
+
+// tagsByName liefert die Tags zu den Namen und legt fehlende neu an.
+func tagsByName(db *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	for _, name := range names {
+		tag := models.Tag{Name: name, Slug: slug.Make(name)}
+		if err := db.Where("slug = ?", tag.Slug).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// taggedWith filtert nach ?tags=go,docker (Namen oder Slugs). Standardmäßig
+// reicht einer der Tags, mit ?tags_match=all müssen alle passen.
+func taggedWith(c *gin.Context, table, joinTable, idColumn string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		value := c.Query("tags")
+		if value == "" {
+			value = c.Query("tag")
+		}
+		names := splitTags(value)
+		if len(names) == 0 {
+			return db
+		}
+
+		slugs := make([]string, len(names))
+		for i, name := range names {
+			slugs[i] = slug.Make(name)
+		}
+
+		sub := database.DB.Table(joinTable).
+			Select(joinTable+"."+idColumn).
+			Joins("JOIN tags ON tags.id = "+joinTable+".tag_id").
+			Where("tags.slug IN ?", slugs)
+		if c.Query("tags_match") == "all" {
+			sub = sub.Group(joinTable+"."+idColumn).Having("COUNT(DISTINCT tags.id) = ?", len(slugs))
+		}
+		return db.Where(table+".id IN (?)", sub)
+	}
+}
+
+// loadTagCounts füllt BlogCount und ProjectCount. Gezählt werden nur Blogs,
+// die der Aufrufer auch sehen darf.
+func loadTagCounts(c *gin.Context, tags []models.Tag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tags))
+	for i := range tags {
+		ids[i] = tags[i].ID
+	}
+
+	type count struct {
+		TagID string
+		Count int64
+	}
+	var blogCounts, projectCounts []count
+
+	err := database.DB.Table("blog_tags").
+		Select("blog_tags.tag_id, COUNT(*) AS count").
+		Joins("JOIN blogs ON blogs.id = blog_tags.blog_id").
+		Scopes(visibleBlogs(c)).
+		Where("blog_tags.tag_id IN ?", ids).
+		Group("blog_tags.tag_id").
+		Scan(&blogCounts).Error
+	if err != nil {
+		return err
+	}
+	err = database.DB.Table("project_tags").
+		Select("tag_id, COUNT(*) AS count").
+		Where("tag_id IN ?", ids).
+		Group("tag_id").
+		Scan(&projectCounts).Error
+	if err != nil {
+		return err
+	}
+
+	byID := map[string]*models.Tag{}
+	for i := range tags {
+		byID[tags[i].ID] = &tags[i]
+	}
+	for _, row := range blogCounts {
+		byID[row.TagID].BlogCount = row.Count
+	}
+	for _, row := range projectCounts {
+		byID[row.TagID].ProjectCount = row.Count
+	}
+	return nil
+}
+
+// GetTags listet alle Tags mit ihrer Verwendung, die meistgenutzten zuerst.
+// Mit ?unused=false werden ungenutzte Tags ausgeblendet.
+func GetTags(c *gin.Context) {
+	tags := []models.Tag{}
+	if err := database.DB.Order("name ASC").Find(&tags).Error; err != nil {
+		problem.Database(c, err, "Failed to load tags")
+		return
+	}
+	if err := loadTagCounts(c, tags); err != nil {
+		problem.Database(c, err, "Failed to load tag counts")
+		return
+	}
+
+	result := make([]models.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if c.Query("unused") == "false" && tag.BlogCount+tag.ProjectCount == 0 {
+			continue
+		}
+		result = append(result, tag)
+	}
+	// Stabil sortieren, damit gleich häufige Tags alphabetisch bleiben
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].BlogCount+result[i].ProjectCount > result[j].BlogCount+result[j].ProjectCount
+	})
+	c.JSON(http.StatusOK, result)
+}
+
+func DeleteTag(c *gin.Context) {
+	var tag models.Tag
+	if err := database.DB.First(&tag, "id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "Tag not found")
+		return
+	}
+
+	tx := database.DB.Begin()
+
+	if err := tx.Exec("DELETE FROM blog_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to delete tag relations")
+		return
+	}
+
+	if err := tx.Exec("DELETE FROM project_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to delete tag relations")
+		return
+	}
+
+	if err := tx.Delete(&tag).Error; err != nil {
+		tx.Rollback()
+		problem.Database(c, err, "Failed to delete tag")
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		problem.Database(c, err, "Failed to delete tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted"})
+}
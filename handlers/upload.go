@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"PortfolioAPI/imaging"
+	"PortfolioAPI/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uploadRule legt fest, welche Dateien für ein Feld erlaubt sind.
+// AllowedTypes bildet den erkannten MIME-Type auf die Dateiendung ab, unter
+// der die Datei gespeichert wird.
+type uploadRule struct {
+	MaxSize      int64
+	AllowedTypes map[string]string
+}
+
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+var (
+	avatarUpload       = uploadRule{MaxSize: 2 << 20, AllowedTypes: imageTypes}
+	blogImageUpload    = uploadRule{MaxSize: 5 << 20, AllowedTypes: imageTypes}
+	projectImageUpload = uploadRule{MaxSize: 5 << 20, AllowedTypes: imageTypes}
+	languageIconUpload = uploadRule{
+		MaxSize: 1 << 20,
+		AllowedTypes: map[string]string{
+			"image/png":    ".png",
+			"image/webp":   ".webp",
+			"image/x-icon": ".ico",
+		},
+	}
+)
+
+// Endungen, die der Client für einen MIME-Type angeben darf
+var extensionAliases = map[string][]string{
+	".jpg": {".jpg", ".jpeg", ".jfif"},
+	".ico": {".ico", ".cur"},
+}
+
+// validatedUpload ist eine geprüfte Datei samt erkanntem Typ.
+type validatedUpload struct {
+	File     *multipart.FileHeader
+	Ext      string
+	MimeType string
+}
+
+type uploadError struct {
+	Status  int
+	Message string
+}
+
+// respond schreibt den Fehler als Problem mit Feldfehler für field.
+func (e *uploadError) respond(c *gin.Context, field, message string) {
+	problem.Write(c, problem.Problem{
+		Status: e.Status,
+		Detail: message,
+		Errors: []problem.FieldError{{Field: field, Code: problem.FieldInvalid, Message: message}},
+	})
+}
+
+func (e *uploadError) Error() string {
+	return e.Message
+}
+
+// validateUpload prüft Größe und tatsächlichen Inhalt einer Datei und
+// liefert die Endung, unter der sie gespeichert werden soll. Die Endung aus
+// file.Filename wird nie übernommen.
+func validateUpload(file *multipart.FileHeader, rule uploadRule) (*validatedUpload, *uploadError) {
+	if file.Size > rule.MaxSize {
+		return nil, &uploadError{
+			Status:  http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("File is too large (max %d MB)", rule.MaxSize>>20),
+		}
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, &uploadError{Status: http.StatusBadRequest, Message: "Failed to read uploaded file"}
+	}
+	defer f.Close()
+
+	// DetectContentType betrachtet höchstens die ersten 512 Bytes
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, &uploadError{Status: http.StatusBadRequest, Message: "Failed to read uploaded file"}
+	}
+	mimeType := http.DetectContentType(head[:n])
+
+	ext, ok := rule.AllowedTypes[mimeType]
+	if !ok {
+		return nil, &uploadError{
+			Status:  http.StatusUnsupportedMediaType,
+			Message: fmt.Sprintf("File type %s is not allowed", mimeType),
+		}
+	}
+
+	// Abmessungen vor dem Speichern prüfen, nicht erst beim Dekodieren.
+	// Dateien ohne lesbaren Header bleiben erlaubt, sie bekommen nur keine
+	// Varianten.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, &uploadError{Status: http.StatusBadRequest, Message: "Failed to read uploaded file"}
+	}
+	if err := imaging.CheckSize(f); imaging.IsTooLarge(err) {
+		return nil, &uploadError{
+			Status:  http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("Image dimensions are too large (max %d megapixels)", imaging.MaxPixels/1_000_000),
+		}
+	}
+
+	clientExt := strings.ToLower(filepath.Ext(file.Filename))
+	if clientExt != "" && !extensionMatches(clientExt, ext) {
+		return nil, &uploadError{
+			Status:  http.StatusUnsupportedMediaType,
+			Message: fmt.Sprintf("File extension %s does not match content type %s", clientExt, mimeType),
+		}
+	}
+
+	return &validatedUpload{File: file, Ext: ext, MimeType: mimeType}, nil
+}
+
+func extensionMatches(clientExt, ext string) bool {
+	if aliases, ok := extensionAliases[ext]; ok {
+		for _, alias := range aliases {
+			if clientExt == alias {
+				return true
+			}
+		}
+		return false
+	}
+	return clientExt == ext
+}
+
+// formUpload liest ein optionales Upload-Feld und validiert es. Ist kein
+// Upload vorhanden, ist upload nil. Bei ungültigen Dateien wurde bereits eine
+// Fehlerantwort geschrieben und ok ist false.
+func formUpload(c *gin.Context, field string, rule uploadRule) (upload *validatedUpload, ok bool) {
+	file, err := c.FormFile(field)
+	if err != nil {
+		return nil, true
+	}
+
+	upload, uploadErr := validateUpload(file, rule)
+	if uploadErr != nil {
+		uploadErr.respond(c, field, uploadErr.Message)
+		return nil, false
+	}
+	return upload, true
+}
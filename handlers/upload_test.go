@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"testing"
+)
+
+// fileHeader verpackt content als hochgeladene Datei.
+func fileHeader(t *testing.T, filename string, content []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return form.File["file"][0]
+}
+
+func encodedImage(t *testing.T, encode func(*bytes.Buffer, image.Image) error) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// hugePNG ist ein gültiger PNG-Header mit 10000 x 10000 Pixeln, aber ohne
+// Bilddaten.
+func hugePNG() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], 10000)
+	binary.BigEndian.PutUint32(ihdr[4:], 10000)
+	ihdr[8], ihdr[9] = 8, 6 // 8 Bit RGBA
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestValidateUpload(t *testing.T) {
+	pngData := encodedImage(t, func(b *bytes.Buffer, img image.Image) error { return png.Encode(b, img) })
+	jpegData := encodedImage(t, func(b *bytes.Buffer, img image.Image) error { return jpeg.Encode(b, img, nil) })
+	icoData := append([]byte{0, 0, 1, 0, 1, 0}, make([]byte, 40)...)
+
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		rule     uploadRule
+		status   int
+		ext      string
+	}{
+		{"png", "photo.png", pngData, blogImageUpload, 0, ".png"},
+		{"jpeg with alias", "photo.JPEG", jpegData, blogImageUpload, 0, ".jpg"},
+		{"no extension", "photo", pngData, blogImageUpload, 0, ".png"},
+		{"extension does not match content", "photo.png", jpegData, blogImageUpload, http.StatusUnsupportedMediaType, ""},
+		{"php disguised as png", "shell.png", []byte("<?php system($_GET['c']); ?>"), blogImageUpload, http.StatusUnsupportedMediaType, ""},
+		{"html", "page.html", []byte("<html><body>hi</body></html>"), blogImageUpload, http.StatusUnsupportedMediaType, ""},
+		{"too large", "big.png", append(pngData, make([]byte, 2<<20)...), avatarUpload, http.StatusRequestEntityTooLarge, ""},
+		{"too many pixels", "huge.png", hugePNG(), blogImageUpload, http.StatusRequestEntityTooLarge, ""},
+		{"ico for language icon", "icon.ico", icoData, languageIconUpload, 0, ".ico"},
+		{"jpeg for language icon", "icon.jpg", jpegData, languageIconUpload, http.StatusUnsupportedMediaType, ""},
+		{"empty file", "empty.png", nil, blogImageUpload, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upload, uploadErr := validateUpload(fileHeader(t, tt.filename, tt.content), tt.rule)
+			if tt.status != 0 {
+				if uploadErr == nil {
+					t.Fatalf("expected status %d, upload was accepted as %s", tt.status, upload.MimeType)
+				}
+				if uploadErr.Status != tt.status {
+					t.Errorf("status = %d, want %d (%s)", uploadErr.Status, tt.status, uploadErr.Message)
+				}
+				return
+			}
+			if uploadErr != nil {
+				t.Fatalf("rejected: %d %s", uploadErr.Status, uploadErr.Message)
+			}
+			if upload.Ext != tt.ext {
+				t.Errorf("ext = %q, want %q", upload.Ext, tt.ext)
+			}
+		})
+	}
+}
+
+func TestExtensionMatches(t *testing.T) {
+	tests := []struct {
+		clientExt, ext string
+		want           bool
+	}{
+		{".png", ".png", true},
+		{".jpeg", ".jpg", true},
+		{".jfif", ".jpg", true},
+		{".png", ".jpg", false},
+		{".cur", ".ico", true},
+		{".jpg", ".webp", false},
+	}
+	for _, tt := range tests {
+		if got := extensionMatches(tt.clientExt, tt.ext); got != tt.want {
+			t.Errorf("extensionMatches(%q, %q) = %v, want %v", tt.clientExt, tt.ext, got, tt.want)
+		}
+	}
+}
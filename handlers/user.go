@@ -1,17 +1,19 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
 
+	"PortfolioAPI/auth"
 	"PortfolioAPI/database"
 	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 func getCDNURL() string {
@@ -32,32 +34,92 @@ func addCDNPrefix(user *models.User) {
 	}
 }
 
+// userListSpec legt Filter und Sortierung für GET /users fest.
+var userListSpec = listSpec{
+	Table: "users",
+	Sorts: []string{"name", "role", "created_at"},
+	Filters: map[string]filterField{
+		"name":       {Column: "name"},
+		"role":       {Column: "role"},
+		"created_at": {Column: "created_at", Type: filterTime},
+	},
+	DefaultSort: []sortKey{{"name", false}},
+}
+
+// userFieldSpec legt ?fields= und ?include= für User fest.
+var userFieldSpec = fieldSpec{
+	Table:  "users",
+	Fields: []string{"id", "name", "email", "avatar", "avatar_media_id", "role", "created_at", "updated_at"},
+	Includes: map[string]includeFunc{
+		"blogs": func(c *gin.Context, query *gorm.DB) *gorm.DB {
+			return query.Preload("Blogs", visibleBlogs(c))
+		},
+	},
+}
+
 func GetUsers(c *gin.Context) {
 	users := []models.User{}
-	database.DB.Find(&users)
+	query, keys, err := listQuery(c, userListSpec, database.DB)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	query, keep, err := selectFields(c, userFieldSpec, query, keys)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if err := paginate(c, query, keys, &users); err != nil {
+		respondPaginationError(c, err)
+		return
+	}
 	for i := range users {
 		addCDNPrefix(&users[i])
 	}
-	c.JSON(http.StatusOK, users)
+	result, err := sparseJSON(users, keep)
+	if err != nil {
+		problem.Internal(c, problem.CodeInternal, err, "Failed to encode response")
+		return
+	}
+	c.JSON(http.StatusOK, result)
 }
 
 func GetUser(c *gin.Context) {
 	var user models.User
-	if err := database.DB.Preload("Blogs").First(&user, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	// Einzelne User kommen standardmäßig mit ihren Blogs
+	spec := userFieldSpec
+	spec.DefaultIncludes = []string{"blogs"}
+	query, keep, err := selectFields(c, spec, database.DB, nil)
+	if err != nil {
+		respondPaginationError(c, err)
+		return
+	}
+	if err := query.First(&user, "users.id = ?", c.Param("id")).Error; err != nil {
+		problem.Lookup(c, err, "User not found")
 		return
 	}
 	addCDNPrefix(&user)
-	c.JSON(http.StatusOK, user)
+	result, err := sparseJSON(user, keep)
+	if err != nil {
+		problem.Internal(c, problem.CodeInternal, err, "Failed to encode user")
+		return
+	}
+	c.JSON(http.StatusOK, result)
 }
 
 func CreateUser(c *gin.Context) {
 	name := c.PostForm("name")
 	email := c.PostForm("email")
 	avatarURL := c.PostForm("avatar_url")
+	role := c.PostForm("role")
+	password := c.PostForm("password")
 
 	if name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		problem.Field(c, "name", problem.FieldRequired, "Name is required")
+		return
+	}
+	if role != "" && !models.IsValidRole(role) {
+		problem.Field(c, "role", problem.FieldInvalid, "Invalid role")
 		return
 	}
 
@@ -66,6 +128,7 @@ func CreateUser(c *gin.Context) {
 	user := models.User{
 		ID:   userID,
 		Name: name,
+		Role: role,
 	}
 
 	// Email setzen (nur wenn nicht leer)
@@ -73,44 +136,45 @@ func CreateUser(c *gin.Context) {
 		user.Email = &email
 	}
 
+	// Passwort setzen (optional, ohne Passwort kein Login möglich)
+	if password != "" {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			problem.Field(c, "password", problem.FieldInvalid, err.Error())
+			return
+		}
+		user.PasswordHash = hash
+	}
+
 	// Avatar URL gesetzt? Dann verwenden
 	if avatarURL != "" {
 		user.Avatar = avatarURL
 	}
 
-	// Bild hochgeladen?
-	file, err := c.FormFile("avatar")
-	if err == nil {
-		// User Ordner erstellen
-		userDir := filepath.Join("public", "users", userID)
-		if err := os.MkdirAll(userDir, 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user directory"})
-			return
-		}
-
-		// Dateiendung beibehalten
-		ext := filepath.Ext(file.Filename)
-		filename := fmt.Sprintf("avatar%s", ext)
-		filePath := filepath.Join(userDir, filename)
-
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save avatar"})
-			return
-		}
-
-		user.Avatar = fmt.Sprintf("/users/%s/%s", userID, filename)
+	// Bild hochgeladen oder aus der Mediathek gewählt?
+	avatar, ok := mediaFromForm(c, "avatar", "avatar_media_id", avatarUpload)
+	if !ok {
+		return
+	}
+	media, ok := avatar.save(c)
+	if !ok {
+		return
+	}
+	defer avatar.cleanup(c)
+	if media != nil {
+		user.Avatar = media.URL
+		user.AvatarMediaID = &media.ID
 	}
 
 	if err := database.DB.Create(&user).Error; err != nil {
-		// Bei Fehler: Ordner wieder löschen falls erstellt
-		os.RemoveAll(filepath.Join("public", "users", userID))
-		if strings.Contains(err.Error(), "Duplicate entry") || strings.Contains(err.Error(), "UNIQUE constraint") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Email already exists"})
+		if problem.IsDuplicate(err) {
+			problem.Taken(c, "email", "Email already exists")
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+			problem.Database(c, err, "Failed to create user")
 		}
 		return
 	}
+	avatar.keep()
 
 	addCDNPrefix(&user)
 	c.JSON(http.StatusCreated, user)
@@ -119,17 +183,34 @@ func CreateUser(c *gin.Context) {
 func UpdateUser(c *gin.Context) {
 	var user models.User
 	if err := database.DB.First(&user, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		problem.Lookup(c, err, "User not found")
 		return
 	}
 
 	name := c.PostForm("name")
 	email := c.PostForm("email")
 	avatarURL := c.PostForm("avatar_url")
+	role := c.PostForm("role")
+	password := c.PostForm("password")
 
 	if name != "" {
 		user.Name = name
 	}
+	if password != "" {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			problem.Field(c, "password", problem.FieldInvalid, err.Error())
+			return
+		}
+		user.PasswordHash = hash
+	}
+	if role != "" {
+		if !models.IsValidRole(role) {
+			problem.Field(c, "role", problem.FieldInvalid, "Invalid role")
+			return
+		}
+		user.Role = role
+	}
 	// Email setzen: wenn leer, dann nil (erlaubt mehrere Benutzer ohne Email)
 	if email != "" {
 		user.Email = &email
@@ -138,45 +219,33 @@ func UpdateUser(c *gin.Context) {
 	}
 	if avatarURL != "" {
 		user.Avatar = avatarURL
+		user.AvatarMediaID = nil
 	}
 
-	// Bild hochgeladen?
-	file, err := c.FormFile("avatar")
-	if err == nil {
-		// User Ordner erstellen
-		userDir := filepath.Join("public", "users", user.ID)
-		if err := os.MkdirAll(userDir, 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user directory"})
-			return
-		}
-
-		// Alte Avatar-Dateien löschen
-		matches, _ := filepath.Glob(filepath.Join(userDir, "avatar.*"))
-		for _, match := range matches {
-			os.Remove(match)
-		}
-
-		// Dateiendung beibehalten
-		ext := filepath.Ext(file.Filename)
-		filename := fmt.Sprintf("avatar%s", ext)
-		filePath := filepath.Join(userDir, filename)
-
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save avatar"})
-			return
-		}
-
-		user.Avatar = fmt.Sprintf("/users/%s/%s", user.ID, filename)
+	// Bild hochgeladen oder aus der Mediathek gewählt?
+	avatar, ok := mediaFromForm(c, "avatar", "avatar_media_id", avatarUpload)
+	if !ok {
+		return
+	}
+	media, ok := avatar.save(c)
+	if !ok {
+		return
+	}
+	defer avatar.cleanup(c)
+	if media != nil {
+		user.Avatar = media.URL
+		user.AvatarMediaID = &media.ID
 	}
 
 	if err := database.DB.Save(&user).Error; err != nil {
-		if strings.Contains(err.Error(), "Duplicate entry") || strings.Contains(err.Error(), "UNIQUE constraint") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Email already exists"})
+		if problem.IsDuplicate(err) {
+			problem.Taken(c, "email", "Email already exists")
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+			problem.Database(c, err, "Failed to update user")
 		}
 		return
 	}
+	avatar.keep()
 
 	addCDNPrefix(&user)
 	c.JSON(http.StatusOK, user)
@@ -185,28 +254,39 @@ func UpdateUser(c *gin.Context) {
 func DeleteUser(c *gin.Context) {
 	var user models.User
 	if err := database.DB.First(&user, "id = ?", c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		problem.Lookup(c, err, "User not found")
 		return
 	}
 
 	// Erst die Verknüpfungen in blog_authors löschen
 	if err := database.DB.Exec("DELETE FROM blog_authors WHERE user_id = ?", user.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user blog relations"})
+		problem.Database(c, err, "Failed to delete user blog relations")
 		return
 	}
 
 	// Verknüpfungen in project_authors löschen
 	if err := database.DB.Exec("DELETE FROM project_authors WHERE user_id = ?", user.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user project relations"})
+		problem.Database(c, err, "Failed to delete user project relations")
+		return
+	}
+
+	// Sessions des Users löschen
+	if err := database.DB.Where("user_id = ?", user.ID).Delete(&models.Session{}).Error; err != nil {
+		problem.Database(c, err, "Failed to delete user sessions")
+		return
+	}
+
+	// Kommentare des Users bleiben mit Namen erhalten
+	if err := database.DB.Model(&models.Comment{}).Where("user_id = ?", user.ID).Update("user_id", nil).Error; err != nil {
+		problem.Database(c, err, "Failed to unlink user comments")
 		return
 	}
 
-	// User Ordner löschen falls vorhanden
-	userDir := filepath.Join("public", "users", user.ID)
-	os.RemoveAll(userDir)
+	// Alten Avatar-Ordner löschen falls vorhanden (Uploads vor der Mediathek)
+	storage.Store.DeletePrefix(c.Request.Context(), "users/"+user.ID)
 
 	if err := database.DB.Delete(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		problem.Database(c, err, "Failed to delete user")
 		return
 	}
 
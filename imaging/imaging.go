@@ -0,0 +1,146 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Size ist eine Zielbreite für responsive Varianten.
+type Size struct {
+	Name  string
+	Width int
+}
+
+var Sizes = []Size{
+	{"thumbnail", 320},
+	{"medium", 768},
+	{"large", 1536},
+}
+
+// Variant ist eine fertig kodierte, verkleinerte Version eines Bildes.
+type Variant struct {
+	Name     string
+	Width    int
+	Height   int
+	Ext      string
+	MimeType string
+	Data     []byte
+}
+
+// Supported gibt an, ob für den MIME-Type Varianten erzeugt werden.
+// GIFs werden übersprungen, da beim Verkleinern die Animation verloren geht.
+func Supported(mimeType string) bool {
+	return mimeType == "image/jpeg" || mimeType == "image/png" || mimeType == "image/webp"
+}
+
+// MaxPixels begrenzt Breite mal Höhe. Ein kleines PNG oder WebP kann riesige
+// Abmessungen angeben und beim Dekodieren Gigabytes an Speicher belegen.
+const MaxPixels = 40_000_000
+
+var ErrTooManyPixels = fmt.Errorf("image is larger than %d megapixels", MaxPixels/1_000_000)
+
+// CheckSize liest nur den Header eines Bildes und prüft die Abmessungen.
+func CheckSize(r io.Reader) error {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return err
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return ErrTooManyPixels
+	}
+	return nil
+}
+
+// Decode liest ein Bild in einem der unterstützten Formate. Zu große Bilder
+// werden vor dem Dekodieren mit ErrTooManyPixels abgelehnt.
+func Decode(r io.ReadSeeker) (image.Image, error) {
+	if err := CheckSize(r); err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// IsTooLarge meldet, ob err auf zu große Abmessungen zurückgeht.
+func IsTooLarge(err error) bool {
+	return errors.Is(err, ErrTooManyPixels)
+}
+
+// Variants erzeugt für jede Größe, die kleiner als das Original ist, eine
+// Variante als JPEG (bzw. PNG bei PNG-Originalen und transparenten Bildern)
+// und eine WebP-Version.
+func Variants(img image.Image, mimeType string) ([]Variant, error) {
+	bounds := img.Bounds()
+	var variants []Variant
+
+	for _, size := range Sizes {
+		if size.Width >= bounds.Dx() {
+			continue
+		}
+
+		height := bounds.Dy() * size.Width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+		dst := image.NewNRGBA(image.Rect(0, 0, size.Width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+		fallback, err := encodeFallback(dst, mimeType)
+		if err != nil {
+			return nil, err
+		}
+		fallback.Name = size.Name
+		variants = append(variants, fallback)
+
+		var webp bytes.Buffer
+		if err := nativewebp.Encode(&webp, dst, nil); err != nil {
+			return nil, err
+		}
+		variants = append(variants, Variant{
+			Name:     size.Name,
+			Width:    size.Width,
+			Height:   height,
+			Ext:      ".webp",
+			MimeType: "image/webp",
+			Data:     webp.Bytes(),
+		})
+	}
+
+	return variants, nil
+}
+
+func encodeFallback(img *image.NRGBA, mimeType string) (Variant, error) {
+	var buf bytes.Buffer
+	variant := Variant{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+
+	// Transparenz bleibt nur in PNG erhalten
+	if mimeType == "image/png" || !img.Opaque() {
+		if err := png.Encode(&buf, img); err != nil {
+			return variant, err
+		}
+		variant.Ext = ".png"
+		variant.MimeType = "image/png"
+	} else {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 82}); err != nil {
+			return variant, err
+		}
+		variant.Ext = ".jpg"
+		variant.MimeType = "image/jpeg"
+	}
+
+	variant.Data = buf.Bytes()
+	return variant, nil
+}
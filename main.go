@@ -1,10 +1,16 @@
 package main
 
 import (
+	"net/http"
 	"os"
 
 	"PortfolioAPI/database"
 	"PortfolioAPI/handlers"
+	"PortfolioAPI/middleware"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+	"PortfolioAPI/scheduler"
+	"PortfolioAPI/storage"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -12,57 +18,144 @@ import (
 
 func main() {
 	database.Connect()
+	storage.Init()
+	scheduler.StartBlogPublisher()
 
-	// Public Ordner erstellen falls nicht vorhanden
-	os.MkdirAll("public/users", 0755)
-	os.MkdirAll("public/blogs", 0755)
-	os.MkdirAll("public/languages", 0755)
-	os.MkdirAll("public/projects", 0755)
+	r := gin.New()
+	r.Use(gin.Logger(), middleware.RequestID(), middleware.Recovery())
 
-	r := gin.Default()
+	// ClientIP nur aus X-Forwarded-For von Proxys aus TRUSTED_PROXIES
+	if err := r.SetTrustedProxies(middleware.TrustedProxiesFromEnv()); err != nil {
+		panic("Invalid TRUSTED_PROXIES: " + err.Error())
+	}
+
+	// Unbekannte Routes ebenfalls als problem+json beantworten
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(func(c *gin.Context) {
+		problem.Abort(c, http.StatusNotFound, "Route not found")
+	})
+	r.NoMethod(func(c *gin.Context) {
+		problem.Abort(c, http.StatusMethodNotAllowed, "Method not allowed")
+	})
 
 	// CORS Middleware (muss vor den Routes kommen)
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001", "http://127.0.0.1:3000", "http://127.0.0.1:3001", "https://admin.canyigit.com", "https://www.canyigit.com", "https://canyigit.com"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "Expires", "Cache-Control", "Pragma"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "Expires", "Cache-Control", "Pragma", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "Retry-After", "Location", "Link", "X-Total-Count", "X-Next-Cursor", "X-Request-ID"},
 		AllowCredentials: true,
 	}))
 
-	// CDN Route für statische Dateien
-	r.Static("/cdn", "./public")
+	// CDN Route für statische Dateien (nur beim lokalen Storage, bei S3
+	// zeigt CDN_URL direkt auf den Bucket)
+	if local, ok := storage.Store.(*storage.Local); ok {
+		r.Static("/cdn", local.Dir)
+	}
+
+	// Rate Limits (Token Bucket, konfigurierbar über RATE_LIMIT_* z.B. "5/m")
+	limiter := middleware.NewMemoryStore()
+	loginLimit := middleware.RateLimit(limiter, "login", middleware.LimitFromEnv("RATE_LIMIT_LOGIN", "5/m"),
+		middleware.ByIP, middleware.ByFormField("email", "username"))
+	writeLimit := middleware.RateLimit(limiter, "write", middleware.LimitFromEnv("RATE_LIMIT_WRITE", "60/m"),
+		middleware.ByIP, middleware.ByCredential)
+	uploadLimit := middleware.RateLimit(limiter, "upload", middleware.LimitFromEnv("RATE_LIMIT_UPLOAD", "20/m"),
+		middleware.UploadsByIP)
+	commentLimit := middleware.RateLimit(limiter, "comment", middleware.LimitFromEnv("RATE_LIMIT_COMMENT", "5/m"),
+		middleware.ByIP)
+
+	r.POST("/auth/login", loginLimit, handlers.Login)
+	r.POST("/auth/refresh", loginLimit, handlers.RefreshToken)
+
+	// Schreibende Routes nur mit gültigem Token
+	protected := r.Group("/")
+	protected.Use(writeLimit, middleware.AuthRequired(), uploadLimit)
+
+	protected.POST("/auth/logout", handlers.Logout)
+	protected.PUT("/auth/password", handlers.ChangePassword)
+
+	// Verwaltung von Usern und API Keys nur für Admins
+	admins := protected.Group("/")
+	admins.Use(middleware.RequireRole(models.RoleAdmin))
+
+	// Kategorien und Sprachen nur für Admins und Editoren
+	editors := protected.Group("/")
+	editors.Use(middleware.RequireRole(models.RoleAdmin, models.RoleEditor))
+
+	admins.GET("/api-keys", handlers.GetAPIKeys)
+	admins.POST("/api-keys", handlers.CreateAPIKey)
+	admins.DELETE("/api-keys/:id", handlers.RevokeAPIKey)
+
+	blogAuthors := middleware.RequireAuthorOf("blog_authors", "blog_id")
+	projectAuthors := middleware.RequireAuthorOf("project_authors", "project_id")
+
+	// Die Liste enthält auch Bilder von Entwürfen
+	protected.GET("/media", handlers.GetMediaList)
+	r.GET("/media/:id", handlers.GetMedia)
+	protected.POST("/media", handlers.CreateMedia)
+	editors.DELETE("/media/:id", handlers.DeleteMedia)
+
+	// Öffentliche Routes, die eingeloggten Usern auch Entwürfe zeigen
+	viewer := middleware.OptionalAuth()
 
 	r.GET("/users", handlers.GetUsers)
-	r.GET("/users/:id", handlers.GetUser)
-	r.POST("/users", handlers.CreateUser)
-	r.PUT("/users/:id", handlers.UpdateUser)
-	r.DELETE("/users/:id", handlers.DeleteUser)
-
-	r.GET("/blogs", handlers.GetBlogs)
-	r.GET("/blogs/:id", handlers.GetBlog)
-	r.GET("/blogs/slug/:slug", handlers.GetBlogBySlug)
-	r.POST("/blogs", handlers.CreateBlog)
-	r.PUT("/blogs/:id", handlers.UpdateBlog)
-	r.DELETE("/blogs/:id", handlers.DeleteBlog)
+	r.GET("/users/:id", viewer, handlers.GetUser)
+	admins.POST("/users", handlers.CreateUser)
+	admins.PUT("/users/:id", handlers.UpdateUser)
+	admins.DELETE("/users/:id", handlers.DeleteUser)
+
+	r.GET("/blogs", viewer, handlers.GetBlogs)
+	r.GET("/blogs/:id", viewer, handlers.GetBlog)
+	r.GET("/blogs/slug/:slug", viewer, handlers.GetBlogBySlug)
+	r.GET("/blogs/highlight.css", handlers.GetHighlightCSS)
+	r.GET("/blogs/:id/related", viewer, handlers.GetRelatedBlogs)
+	protected.POST("/blogs", handlers.CreateBlog)
+	protected.PUT("/blogs/:id", blogAuthors, handlers.UpdateBlog)
+	protected.DELETE("/blogs/:id", blogAuthors, handlers.DeleteBlog)
+	r.GET("/blogs/:id/images", viewer, handlers.GetBlogImages)
+	protected.POST("/blogs/:id/images", blogAuthors, handlers.CreateBlogImages)
+	protected.DELETE("/blogs/:id/images/:imageId", blogAuthors, handlers.DeleteBlogImage)
+	protected.GET("/blogs/:id/revisions", blogAuthors, handlers.GetBlogRevisions)
+	protected.GET("/blogs/:id/revisions/diff", blogAuthors, handlers.DiffBlogRevisions)
+	protected.GET("/blogs/:id/revisions/:revisionId", blogAuthors, handlers.GetBlogRevision)
+	protected.POST("/blogs/:id/revisions/:revisionId/restore", blogAuthors, handlers.RestoreBlogRevision)
+
+	// Kommentare: öffentlich lesen und schreiben, Moderation nur für Admins
+	r.GET("/blogs/:id/comments", viewer, handlers.GetBlogComments)
+	r.POST("/blogs/:id/comments", commentLimit, viewer, handlers.CreateComment)
+	admins.GET("/comments", handlers.GetComments)
+	admins.POST("/comments/:id/approve", handlers.ApproveComment)
+	admins.POST("/comments/:id/spam", handlers.MarkCommentSpam)
+	admins.DELETE("/comments/:id", handlers.DeleteComment)
 
 	r.GET("/languages", handlers.GetLanguages)
 	r.GET("/languages/:id", handlers.GetLanguage)
-	r.POST("/languages", handlers.CreateLanguage)
-	r.PUT("/languages/:id", handlers.UpdateLanguage)
-	r.DELETE("/languages/:id", handlers.DeleteLanguage)
+	editors.POST("/languages", handlers.CreateLanguage)
+	editors.PUT("/languages/:id", handlers.UpdateLanguage)
+	editors.DELETE("/languages/:id", handlers.DeleteLanguage)
 
 	r.GET("/projects", handlers.GetProjects)
 	r.GET("/projects/:id", handlers.GetProject)
-	r.POST("/projects", handlers.CreateProject)
-	r.PUT("/projects/:id", handlers.UpdateProject)
-	r.DELETE("/projects/:id", handlers.DeleteProject)
+	protected.POST("/projects", handlers.CreateProject)
+	protected.PUT("/projects/:id", projectAuthors, handlers.UpdateProject)
+	protected.DELETE("/projects/:id", projectAuthors, handlers.DeleteProject)
+
+	r.GET("/search", viewer, handlers.Search)
+
+	r.GET("/series", viewer, handlers.GetSeriesList)
+	r.GET("/series/:id", viewer, handlers.GetSeries)
+	editors.POST("/series", handlers.CreateSeries)
+	editors.PUT("/series/:id", handlers.UpdateSeries)
+	editors.DELETE("/series/:id", handlers.DeleteSeries)
+
+	r.GET("/tags", viewer, handlers.GetTags)
+	editors.DELETE("/tags/:id", handlers.DeleteTag)
 
 	r.GET("/categories", handlers.GetCategories)
 	r.GET("/categories/:id", handlers.GetCategory)
-	r.POST("/categories", handlers.CreateCategory)
-	r.PUT("/categories/:id", handlers.UpdateCategory)
-	r.DELETE("/categories/:id", handlers.DeleteCategory)
+	editors.POST("/categories", handlers.CreateCategory)
+	editors.PUT("/categories/:id", handlers.UpdateCategory)
+	editors.DELETE("/categories/:id", handlers.DeleteCategory)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,145 @@
+package markdown
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"PortfolioAPI/slug"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var (
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+)
+
+func init() {
+	md = goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(highlightStyle()),
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		// Rohes HTML ist erlaubt, wird danach aber von bluemonday bereinigt
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+
+	// Klassen für das Syntax-Highlighting und die Task-Listen durchlassen
+	policy = bluemonday.UGCPolicy()
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9_\- ]+$`)).OnElements("pre", "code", "span", "div")
+	policy.AllowAttrs("type", "checked", "disabled").OnElements("input")
+}
+
+// highlightStyle kommt aus MARKDOWN_HIGHLIGHT_STYLE, Standard ist "github".
+func highlightStyle() string {
+	if style := os.Getenv("MARKDOWN_HIGHLIGHT_STYLE"); style != "" {
+		return style
+	}
+	return "github"
+}
+
+// headingIDs erzeugt die Anker der Überschriften wie Blog-Slugs, also mit
+// ausgeschriebenen Umlauten. Doppelte Anker bekommen -1, -2, ... angehängt.
+type headingIDs struct {
+	used map[string]bool
+}
+
+func newContext() parser.Context {
+	return parser.NewContext(parser.WithIDs(&headingIDs{used: map[string]bool{}}))
+}
+
+func (ids *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	base := slug.Make(string(value))
+	if base == "" {
+		base = "heading"
+	}
+	id := base
+	for i := 1; ids.used[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	ids.used[id] = true
+	return []byte(id)
+}
+
+func (ids *headingIDs) Put(value []byte) {
+	ids.used[string(value)] = true
+}
+
+// Render wandelt Markdown in bereinigtes HTML um. Codeblöcke bekommen
+// Chroma-Klassen, das passende Stylesheet liefert HighlightCSS.
+func Render(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(source), &buf, parser.WithContext(newContext())); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
+
+// HighlightCSS liefert das Stylesheet für die Klassen im gerenderten Code.
+func HighlightCSS() (string, error) {
+	var buf strings.Builder
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	if err := formatter.WriteCSS(&buf, styles.Get(highlightStyle())); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+type cacheEntry struct {
+	version time.Time
+	html    string
+}
+
+// Cache merkt sich gerenderte Inhalte pro Schlüssel. Ändert sich die
+// Version (z.B. UpdatedAt), wird neu gerendert.
+type Cache struct {
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+func NewCache() *Cache {
+	return &Cache{entries: map[string]cacheEntry{}}
+}
+
+func (c *Cache) Render(key string, version time.Time, source string) (string, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok && entry.version.Equal(version) {
+		return entry.html, nil
+	}
+
+	rendered, err := Render(source)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{version: version, html: rendered}
+	c.mu.Unlock()
+	return rendered, nil
+}
+
+// Forget entfernt einen Eintrag, z.B. wenn der Inhalt gelöscht wurde.
+func (c *Cache) Forget(key string) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
@@ -0,0 +1,138 @@
+package markdown
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+const defaultWordsPerMinute = 200
+
+type Heading struct {
+	Level  int
+	Text   string
+	Anchor string
+}
+
+type Outline struct {
+	WordCount   int
+	ReadingTime int // Minuten
+	Headings    []Heading
+	Text        string // Fließtext ohne Formatierung und Code
+}
+
+// wordsPerMinute kommt aus READING_WORDS_PER_MINUTE, Standard ist 200.
+func wordsPerMinute() int {
+	if value := os.Getenv("READING_WORDS_PER_MINUTE"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultWordsPerMinute
+}
+
+// Analyze zählt die Wörter eines Markdown-Textes, schätzt die Lesezeit und
+// sammelt die Überschriften. Die Anker entsprechen den IDs, die Render an die
+// Überschriften schreibt. Codeblöcke und rohes HTML zählen nicht als Text.
+func Analyze(source string) (Outline, error) {
+	src := []byte(source)
+	doc := md.Parser().Parse(text.NewReader(src), parser.WithContext(newContext()))
+
+	var words strings.Builder
+	outline := Outline{Headings: []Heading{}}
+
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock {
+				words.WriteByte(' ')
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Heading:
+			heading := Heading{Level: node.Level, Text: plainText(node, src)}
+			if id, ok := node.AttributeString("id"); ok {
+				if b, ok := id.([]byte); ok {
+					heading.Anchor = string(b)
+				}
+			}
+			outline.Headings = append(outline.Headings, heading)
+		case *ast.Text:
+			words.Write(node.Value(src))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				words.WriteByte(' ')
+			}
+		case *ast.String:
+			words.Write(node.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return Outline{}, err
+	}
+
+	outline.Text = strings.Join(strings.Fields(words.String()), " ")
+	outline.WordCount = countWords(outline.Text)
+	if outline.WordCount > 0 {
+		wpm := wordsPerMinute()
+		outline.ReadingTime = (outline.WordCount + wpm - 1) / wpm
+	}
+	return outline, nil
+}
+
+// PlainText liefert den lesbaren Text eines Markdown-Textes, z.B. für
+// Suchergebnisse.
+func PlainText(source string) string {
+	outline, err := Analyze(source)
+	if err != nil {
+		return source
+	}
+	return outline.Text
+}
+
+// plainText liefert den Text eines Knotens ohne Formatierung.
+func plainText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := child.(type) {
+		case *ast.Text:
+			b.Write(node.Value(src))
+			if node.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// countWords zählt Folgen von Buchstaben oder Ziffern, damit Satzzeichen
+// und Aufzählungszeichen nicht mitgezählt werden.
+func countWords(s string) int {
+	count := 0
+	inWord := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if !inWord {
+				count++
+				inWord = true
+			}
+		} else if r != '\'' && r != '’' && r != '-' {
+			inWord = false
+		}
+	}
+	return count
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"PortfolioAPI/auth"
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AuthRequired lässt nur Requests mit gültigem Bearer Token oder API Key
+// durch. Die Session wird unter "session" im Context abgelegt, der
+// zugehörige User (nil beim Admin aus den Umgebungsvariablen) unter "user".
+// API Keys landen unter "api_key".
+func AuthRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			problem.Abort(c, http.StatusUnauthorized, "Authorization required")
+			return
+		}
+
+		if status, message := authenticate(c, token); status != 0 {
+			problem.Abort(c, status, message)
+			return
+		}
+		c.Next()
+	}
+}
+
+// OptionalAuth wertet einen vorhandenen Token aus, lässt aber auch Requests
+// ohne oder mit ungültigem Token als anonym durch. Für öffentliche Routes,
+// die eingeloggten Usern mehr zeigen (z.B. Entwürfe).
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := bearerToken(c); token != "" {
+			authenticate(c, token)
+		}
+		c.Next()
+	}
+}
+
+// IsAuthenticated gibt an, ob der Request eine gültige Session oder einen
+// API Key hat.
+func IsAuthenticated(c *gin.Context) bool {
+	return CurrentSession(c) != nil || CurrentAPIKey(c) != nil
+}
+
+// authenticate prüft token und legt Session, User bzw. API Key im Context
+// ab. Bei Fehlern werden Status und Meldung zurückgegeben, sonst 0.
+func authenticate(c *gin.Context, token string) (int, string) {
+	if auth.IsAPIKey(token) {
+		apiKey, err := auth.ValidateAPIKey(token)
+		if err != nil {
+			return http.StatusUnauthorized, "Invalid or revoked API key"
+		}
+		if apiKey.Scope == models.ScopeRead && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			return http.StatusForbidden, "API key is read-only"
+		}
+		c.Set("api_key", apiKey)
+		return 0, ""
+	}
+
+	session, err := auth.ValidateToken(token)
+	if err != nil {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+
+	var user *models.User
+	if session.UserID != nil {
+		user = &models.User{}
+		if err := database.DB.First(user, "id = ?", *session.UserID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusUnauthorized, "Invalid or expired token"
+		} else if err != nil {
+			return http.StatusInternalServerError, "Failed to load user"
+		}
+	}
+
+	c.Set("session", session)
+	c.Set("user", user)
+	return 0, ""
+}
+
+// CurrentSession liefert die Session des Requests oder nil.
+func CurrentSession(c *gin.Context) *models.Session {
+	value, exists := c.Get("session")
+	if !exists {
+		return nil
+	}
+	session, _ := value.(*models.Session)
+	return session
+}
+
+// CurrentAPIKey liefert den verwendeten API Key oder nil.
+func CurrentAPIKey(c *gin.Context) *models.APIKey {
+	value, exists := c.Get("api_key")
+	if !exists {
+		return nil
+	}
+	apiKey, _ := value.(*models.APIKey)
+	return apiKey
+}
+
+// CurrentUser liefert den eingeloggten User oder nil.
+func CurrentUser(c *gin.Context) *models.User {
+	value, exists := c.Get("user")
+	if !exists {
+		return nil
+	}
+	user, _ := value.(*models.User)
+	return user
+}
+
+// CurrentRole liefert die Rolle des eingeloggten Users. Der Admin aus den
+// Umgebungsvariablen hat immer die Rolle admin. API Keys mit Scope admin
+// zählen als admin, mit Scope write als editor.
+func CurrentRole(c *gin.Context) string {
+	if apiKey := CurrentAPIKey(c); apiKey != nil {
+		switch apiKey.Scope {
+		case models.ScopeAdmin:
+			return models.RoleAdmin
+		case models.ScopeWrite:
+			return models.RoleEditor
+		}
+		return ""
+	}
+	if _, exists := c.Get("session"); !exists {
+		return ""
+	}
+	if user := CurrentUser(c); user != nil {
+		return user.Role
+	}
+	return models.RoleAdmin
+}
+
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+	"PortfolioAPI/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole lässt nur User mit einer der angegebenen Rollen durch.
+// Muss nach AuthRequired laufen.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := CurrentRole(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		problem.Abort(c, http.StatusForbidden, "Insufficient permissions")
+	}
+}
+
+// RequireAuthorOf erlaubt Admins und Editoren jeden Zugriff. Authoren dürfen
+// nur Einträge bearbeiten, bei denen sie in der Join-Tabelle (z.B.
+// blog_authors mit Spalte blog_id) eingetragen sind.
+func RequireAuthorOf(joinTable, idColumn string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := CurrentRole(c)
+		if role == models.RoleAdmin || role == models.RoleEditor {
+			c.Next()
+			return
+		}
+
+		user := CurrentUser(c)
+		if role != models.RoleAuthor || user == nil {
+			problem.Abort(c, http.StatusForbidden, "Insufficient permissions")
+			return
+		}
+
+		var count int64
+		err := database.DB.Table(joinTable).
+			Where(idColumn+" = ? AND user_id = ?", c.Param("id"), user.ID).
+			Count(&count).Error
+		if err != nil {
+			problem.Database(c, err, "Failed to check permissions")
+			return
+		}
+		if count == 0 {
+			problem.Abort(c, http.StatusForbidden, "You are not an author of this entry")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"PortfolioAPI/auth"
+	"PortfolioAPI/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limit beschreibt einen Token Bucket: Rate Tokens pro Sekunde, maximal
+// Burst Tokens auf einmal.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// KeyFunc liefert den Schlüssel, nach dem gezählt wird. Ein leerer
+// Schlüssel bedeutet, dass der Request für diese Regel nicht zählt.
+type KeyFunc func(c *gin.Context) string
+
+// ParseLimit liest Angaben wie "5/m", "100/h" oder "2/s".
+func ParseLimit(value string) (Limit, bool) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return Limit{}, false
+	}
+	count, err := strconv.Atoi(parts[0])
+	if err != nil || count <= 0 {
+		return Limit{}, false
+	}
+
+	var period time.Duration
+	switch parts[1] {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		return Limit{}, false
+	}
+
+	return Limit{Rate: float64(count) / period.Seconds(), Burst: count}, true
+}
+
+// LimitFromEnv liest ein Limit aus der Umgebung, z.B. RATE_LIMIT_LOGIN=5/m.
+func LimitFromEnv(key, fallback string) Limit {
+	if limit, ok := ParseLimit(os.Getenv(key)); ok {
+		return limit
+	}
+	limit, _ := ParseLimit(fallback)
+	return limit
+}
+
+// RateLimit begrenzt Requests pro Schlüssel. Jede KeyFunc bekommt einen
+// eigenen Bucket, der Request wird abgelehnt sobald einer davon leer ist.
+func RateLimit(store RateLimitStore, name string, limit Limit, keys ...KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, keyFunc := range keys {
+			key := keyFunc(c)
+			if key == "" {
+				continue
+			}
+
+			allowed, retryAfter := store.Take(name+":"+key, limit)
+			if !allowed {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				c.Header("Retry-After", strconv.Itoa(seconds))
+				problem.Abort(c, http.StatusTooManyRequests, "Too many requests")
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// TrustedProxiesFromEnv liest TRUSTED_PROXIES, eine kommagetrennte Liste von
+// IPs oder CIDRs (z.B. "127.0.0.1,10.0.0.0/8"). Nur diesen Proxys wird
+// X-Forwarded-For geglaubt. Ohne Angabe gilt die IP der Verbindung, sonst
+// könnte jeder Client mit einem erfundenen Header die Limits pro IP umgehen.
+func TrustedProxiesFromEnv() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// ByIP zählt pro Client-IP. Hinter einem Proxy muss TRUSTED_PROXIES gesetzt
+// sein, sonst teilen sich alle Clients die IP des Proxys.
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByCredential zählt pro Token bzw. API Key aus dem Authorization Header.
+// Gespeichert wird nur der Hash.
+func ByCredential(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		return ""
+	}
+	return "cred:" + auth.HashToken(header)
+}
+
+// ByFormField zählt pro Wert eines Formularfelds, z.B. der Email beim Login.
+func ByFormField(fields ...string) KeyFunc {
+	return func(c *gin.Context) string {
+		for _, field := range fields {
+			if value := strings.ToLower(strings.TrimSpace(c.PostForm(field))); value != "" {
+				return field + ":" + value
+			}
+		}
+		return ""
+	}
+}
+
+// UploadsByIP zählt pro Client-IP, aber nur Multipart-Requests. Der Body
+// wird dafür nicht gelesen, sonst müsste jeder Upload vor allen anderen
+// Prüfungen komplett geparst werden.
+func UploadsByIP(c *gin.Context) string {
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		return ""
+	}
+	return ByIP(c)
+}
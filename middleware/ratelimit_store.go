@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitStore hält die Token Buckets. Neben dem MemoryStore kann z.B.
+// ein Redis-Store eingesetzt werden, wenn mehrere Instanzen laufen.
+type RateLimitStore interface {
+	// Take entnimmt ein Token aus dem Bucket zu key. Ist keins verfügbar,
+	// wird false und die Wartezeit bis zum nächsten Token zurückgegeben.
+	Take(key string, limit Limit) (bool, time.Duration)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryStore ist ein prozesslokaler RateLimitStore.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time // in Tests austauschbar
+}
+
+// NewMemoryStore erstellt einen MemoryStore und räumt inaktive Buckets
+// regelmäßig auf.
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+	go s.cleanup(time.Minute, 10*time.Minute)
+	return s
+}
+
+func (s *MemoryStore) Take(key string, limit Limit) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	// Tokens seit dem letzten Zugriff auffüllen
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	return false, wait
+}
+
+func (s *MemoryStore) cleanup(interval, maxIdle time.Duration) {
+	for range time.Tick(interval) {
+		s.mu.Lock()
+		for key, b := range s.buckets {
+			if time.Since(b.last) > maxIdle {
+				delete(s.buckets, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
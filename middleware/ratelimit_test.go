@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// fakeClock ist eine Uhr, die nur per advance weiterläuft.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return &MemoryStore{buckets: map[string]*bucket{}, now: clock.now}, clock
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Limit
+		ok    bool
+	}{
+		{"5/m", Limit{Rate: 5.0 / 60, Burst: 5}, true},
+		{"2/s", Limit{Rate: 2, Burst: 2}, true},
+		{"100/h", Limit{Rate: 100.0 / 3600, Burst: 100}, true},
+		{"", Limit{}, false},
+		{"5", Limit{}, false},
+		{"0/m", Limit{}, false},
+		{"-1/m", Limit{}, false},
+		{"x/m", Limit{}, false},
+		{"5/d", Limit{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseLimit(tt.value)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLimitFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT_TEST", "3/s")
+	if got := LimitFromEnv("RATE_LIMIT_TEST", "1/m"); got.Burst != 3 {
+		t.Errorf("Burst = %d, want 3", got.Burst)
+	}
+	t.Setenv("RATE_LIMIT_TEST", "invalid")
+	if got := LimitFromEnv("RATE_LIMIT_TEST", "1/m"); got.Burst != 1 {
+		t.Errorf("Burst = %d, want fallback 1", got.Burst)
+	}
+}
+
+func TestMemoryStoreBurst(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Rate: 1, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := store.Take("a", limit); !ok {
+			t.Fatalf("take %d rejected within burst", i+1)
+		}
+	}
+	ok, wait := store.Take("a", limit)
+	if ok {
+		t.Fatal("take beyond burst allowed")
+	}
+	if wait != time.Second {
+		t.Errorf("wait = %v, want 1s", wait)
+	}
+
+	// Andere Schlüssel haben einen eigenen Bucket
+	if ok, _ := store.Take("b", limit); !ok {
+		t.Error("separate key rejected")
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	tests := []struct {
+		name    string
+		advance time.Duration
+		allowed int
+	}{
+		{"no time passed", 0, 0},
+		{"partial token", 500 * time.Millisecond, 0},
+		{"one token", time.Second, 1},
+		{"two tokens", 2500 * time.Millisecond, 2},
+		{"capped at burst", time.Hour, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, clock := newTestStore()
+			limit := Limit{Rate: 1, Burst: 3}
+			for i := 0; i < limit.Burst; i++ {
+				store.Take("a", limit)
+			}
+
+			clock.advance(tt.advance)
+			allowed := 0
+			for i := 0; i < 10; i++ {
+				if ok, _ := store.Take("a", limit); ok {
+					allowed++
+				}
+			}
+			if allowed != tt.allowed {
+				t.Errorf("allowed = %d, want %d", allowed, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreRetryAfterShrinks(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{Rate: 0.5, Burst: 1}
+	store.Take("a", limit)
+
+	_, first := store.Take("a", limit)
+	clock.advance(time.Second)
+	_, second := store.Take("a", limit)
+	if first != 2*time.Second || second != time.Second {
+		t.Errorf("waits = %v, %v, want 2s, 1s", first, second)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	store, _ := newTestStore()
+	r := gin.New()
+	r.POST("/", RateLimit(store, "test", Limit{Rate: 0.1, Burst: 1}, ByIP), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	request := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(); w.Code != http.StatusNoContent {
+		t.Fatalf("first request: status %d", w.Code)
+	}
+	w := request()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "10" {
+		t.Errorf("Retry-After = %q, want 10", got)
+	}
+}
+
+func TestKeyFuncs(t *testing.T) {
+	newContext := func(contentType, body, auth string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+		c.Request.RemoteAddr = "192.0.2.1:1234"
+		if contentType != "" {
+			c.Request.Header.Set("Content-Type", contentType)
+		}
+		if auth != "" {
+			c.Request.Header.Set("Authorization", auth)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		keyFunc KeyFunc
+		c       *gin.Context
+		want    string
+	}{
+		{"ip", ByIP, newContext("", "", ""), "ip:192.0.2.1"},
+		{"upload multipart", UploadsByIP, newContext("multipart/form-data; boundary=x", "", ""), "ip:192.0.2.1"},
+		{"upload json", UploadsByIP, newContext("application/json", "{}", ""), ""},
+		{"credential missing", ByCredential, newContext("", "", ""), ""},
+		{"form field", ByFormField("email"), newContext("application/x-www-form-urlencoded", "email=+A@B.de", ""), "email:a@b.de"},
+		{"form field fallback", ByFormField("email", "username"), newContext("application/x-www-form-urlencoded", "username=Can", ""), "username:can"},
+		{"form field missing", ByFormField("email"), newContext("application/x-www-form-urlencoded", "", ""), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.keyFunc(tt.c); got != tt.want {
+				t.Errorf("key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	key := ByCredential(newContext("", "", "Bearer secret"))
+	if !strings.HasPrefix(key, "cred:") || strings.Contains(key, "secret") {
+		t.Errorf("credential key %q must be a hash", key)
+	}
+}
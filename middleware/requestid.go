@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"fmt"
+	"regexp"
+
+	"PortfolioAPI/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Nur kurze, harmlose IDs vom Client übernehmen, sie landen im Log
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID vergibt jeder Anfrage eine ID, die im Header X-Request-ID
+// zurückgeht und in Fehlerantworten und im Log auftaucht. Eine gültige ID
+// aus dem Request Header (z.B. vom Load Balancer) wird übernommen.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.New().String()
+		}
+		c.Set(problem.RequestIDKey, id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+// Recovery fängt Panics ab und antwortet mit einem Problem statt einer
+// leeren 500.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		problem.Internal(c, problem.CodeInternal, fmt.Errorf("panic: %v", recovered), "Internal server error")
+	})
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+type APIKey struct {
+	ID          string     `json:"id" gorm:"type:char(36);primaryKey"`
+	Name        string     `json:"name" gorm:"type:varchar(255);not null"`
+	Prefix      string     `json:"prefix" gorm:"type:varchar(20);not null"`
+	KeyHash     string     `json:"-" gorm:"type:char(64);uniqueIndex;not null"`
+	Scope       string     `json:"scope" gorm:"type:varchar(20);not null"`
+	CreatedByID *string    `json:"created_by_id" gorm:"type:char(36)"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func IsValidScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeWrite || scope == ScopeAdmin
+}
@@ -2,29 +2,60 @@ package models
 
 import "time"
 
+const (
+	BlogStatusDraft     = "draft"
+	BlogStatusScheduled = "scheduled"
+	BlogStatusPublished = "published"
+	BlogStatusArchived  = "archived"
+)
+
+// BlogHeading ist ein Eintrag im Inhaltsverzeichnis eines Blogs. Anchor
+// entspricht der ID der Überschrift in content_html.
+type BlogHeading struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"`
+}
+
 type Blog struct {
-	ID         uint       `json:"id" gorm:"primaryKey"`
-	Title      string     `json:"title" gorm:"type:varchar(255);not null"`
-	Slug       string     `json:"slug" gorm:"type:varchar(255);uniqueIndex;not null"`
-	Excerpt    string     `json:"excerpt" gorm:"type:varchar(500)"`
-	Content    string     `json:"content" gorm:"type:text"`
-	Image      string     `json:"image" gorm:"type:varchar(500)"`
-	Pinned     bool       `json:"pinned" gorm:"default:false"`
-	Authors    []User     `json:"authors" gorm:"many2many:blog_authors;"`
-	Categories []Category `json:"categories" gorm:"many2many:blog_categories;"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
+	ID             uint          `json:"id" gorm:"primaryKey"`
+	Title          string        `json:"title" gorm:"type:varchar(255);not null"`
+	Slug           string        `json:"slug" gorm:"type:varchar(255);uniqueIndex;not null"`
+	Excerpt        string        `json:"excerpt" gorm:"type:varchar(500)"`
+	Content        string        `json:"content" gorm:"type:text"`
+	ContentHTML    string        `json:"content_html,omitempty" gorm:"-"`
+	WordCount      int           `json:"word_count" gorm:"default:0"`
+	ReadingTime    int           `json:"reading_time" gorm:"default:0"`
+	TOC            []BlogHeading `json:"toc" gorm:"serializer:json;type:text"`
+	Image          string        `json:"image" gorm:"type:varchar(500)"`
+	ImageMediaID   *string       `json:"image_media_id" gorm:"type:char(36);index"`
+	ImageMedia     *Media        `json:"-" gorm:"foreignKey:ImageMediaID"`
+	Images         *ImageSet     `json:"images,omitempty" gorm:"-"`
+	Pinned         bool          `json:"pinned" gorm:"default:false"`
+	Status         string        `json:"status" gorm:"type:varchar(20);not null;default:published;index"`
+	PublishedAt    *time.Time    `json:"published_at" gorm:"index"`
+	Authors        []User        `json:"authors" gorm:"many2many:blog_authors;"`
+	Categories     []Category    `json:"categories" gorm:"many2many:blog_categories;"`
+	Tags           []Tag         `json:"tags" gorm:"many2many:blog_tags;"`
+	SeriesID       *string       `json:"series_id" gorm:"type:char(36);index"`
+	SeriesPosition int           `json:"series_position" gorm:"default:0"`
+	Series         *SeriesInfo   `json:"series,omitempty" gorm:"-"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
 }
 
 type CreateBlogInput struct {
 	Title       string   `json:"title" binding:"required"`
-	Slug        string   `json:"slug" binding:"required"`
+	Slug        string   `json:"slug"`
 	Excerpt     string   `json:"excerpt"`
 	Content     string   `json:"content"`
 	Image       string   `json:"image"`
 	Pinned      bool     `json:"pinned"`
+	Status      string   `json:"status"`
+	PublishedAt string   `json:"published_at"`
 	AuthorIDs   []string `json:"author_ids" binding:"required"`
 	CategoryIDs []string `json:"category_ids"`
+	Tags        []string `json:"tags"`
 }
 
 type UpdateBlogInput struct {
@@ -34,6 +65,14 @@ type UpdateBlogInput struct {
 	Content     string   `json:"content"`
 	Image       string   `json:"image"`
 	Pinned      bool     `json:"pinned"`
+	Status      string   `json:"status"`
+	PublishedAt string   `json:"published_at"`
 	AuthorIDs   []string `json:"author_ids"`
 	CategoryIDs []string `json:"category_ids"`
+	Tags        []string `json:"tags"`
+}
+
+func IsValidBlogStatus(status string) bool {
+	return status == BlogStatusDraft || status == BlogStatusScheduled ||
+		status == BlogStatusPublished || status == BlogStatusArchived
 }
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BlogImage ist ein Bild, das im Content eines Blogs eingebettet wird.
+type BlogImage struct {
+	ID        string    `json:"id" gorm:"type:char(36);primaryKey"`
+	BlogID    uint      `json:"blog_id" gorm:"index;not null"`
+	Filename  string    `json:"filename" gorm:"type:varchar(255);not null"`
+	URL       string    `json:"url" gorm:"type:varchar(500);not null"`
+	MimeType  string    `json:"mime_type" gorm:"type:varchar(100);not null"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (i *BlogImage) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// BlogRevision ist ein vollständiger Stand eines Blogs nach dem Speichern.
+type BlogRevision struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	BlogID      uint       `json:"blog_id" gorm:"index;not null"`
+	Title       string     `json:"title" gorm:"type:varchar(255);not null"`
+	Slug        string     `json:"slug" gorm:"type:varchar(255);not null"`
+	Excerpt     string     `json:"excerpt" gorm:"type:varchar(500)"`
+	Content     string     `json:"content" gorm:"type:text"`
+	Image       string     `json:"image" gorm:"type:varchar(500)"`
+	Pinned      bool       `json:"pinned"`
+	Status      string     `json:"status" gorm:"type:varchar(20)"`
+	PublishedAt *time.Time `json:"published_at"`
+	AuthorIDs   []string   `json:"author_ids" gorm:"serializer:json;type:text"`
+	CategoryIDs []string   `json:"category_ids" gorm:"serializer:json;type:text"`
+	Tags        []string   `json:"tags" gorm:"serializer:json;type:text"`
+	EditorID    *string    `json:"editor_id" gorm:"type:char(36)"`
+	EditorName  string     `json:"editor_name" gorm:"type:varchar(255)"`
+	Note        string     `json:"note" gorm:"type:varchar(255)"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// BlogSlug ist ein früherer Slug eines Blogs. Alte Links werden darüber auf
+// den aktuellen Slug umgeleitet.
+type BlogSlug struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BlogID    uint      `json:"blog_id" gorm:"index;not null"`
+	Slug      string    `json:"slug" gorm:"type:varchar(255);uniqueIndex;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
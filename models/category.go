@@ -8,10 +8,14 @@ import (
 )
 
 type Category struct {
-	ID        string    `json:"id" gorm:"type:char(36);primaryKey"`
-	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          string      `json:"id" gorm:"type:char(36);primaryKey"`
+	Name        string      `json:"name" gorm:"type:varchar(255);not null"`
+	Slug        string      `json:"slug" gorm:"type:varchar(255);uniqueIndex:idx_categories_slug_unique;not null"`
+	Description string      `json:"description" gorm:"type:text"`
+	ParentID    *string     `json:"parent_id" gorm:"type:char(36);index"`
+	Children    []*Category `json:"children,omitempty" gorm:"-"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
 }
 
 func (c *Category) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusSpam     = "spam"
+)
+
+type Comment struct {
+	ID          string     `json:"id" gorm:"type:char(36);primaryKey"`
+	BlogID      uint       `json:"blog_id" gorm:"index;not null"`
+	ParentID    *string    `json:"parent_id" gorm:"type:char(36);index"`
+	UserID      *string    `json:"user_id" gorm:"type:char(36);index"`
+	AuthorName  string     `json:"author_name" gorm:"type:varchar(100);not null"`
+	AuthorEmail string     `json:"author_email,omitempty" gorm:"type:varchar(255)"`
+	Content     string     `json:"content" gorm:"type:text;not null"`
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	SpamReason  string     `json:"spam_reason,omitempty" gorm:"type:varchar(255)"`
+	IP          string     `json:"ip,omitempty" gorm:"type:varchar(45)"`
+	UserAgent   string     `json:"user_agent,omitempty" gorm:"type:varchar(500)"`
+	Replies     []*Comment `json:"replies,omitempty" gorm:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (cm *Comment) BeforeCreate(tx *gorm.DB) error {
+	if cm.ID == "" {
+		cm.ID = uuid.New().String()
+	}
+	if cm.Status == "" {
+		cm.Status = CommentStatusPending
+	}
+	return nil
+}
+
+func IsValidCommentStatus(status string) bool {
+	return status == CommentStatusPending || status == CommentStatusApproved || status == CommentStatusSpam
+}
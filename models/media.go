@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImageVariant ist eine verkleinerte Version eines Bildes, jeweils im
+// Ursprungsformat und als WebP.
+type ImageVariant struct {
+	Name    string `json:"name"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	URL     string `json:"url"`
+	WebPURL string `json:"webp_url"`
+}
+
+// ImageSet fasst die URLs eines Bildes für srcset-Attribute zusammen.
+type ImageSet struct {
+	Original   string `json:"original"`
+	Thumbnail  string `json:"thumbnail,omitempty"`
+	Medium     string `json:"medium,omitempty"`
+	Large      string `json:"large,omitempty"`
+	Srcset     string `json:"srcset"`
+	WebPSrcset string `json:"webp_srcset,omitempty"`
+}
+
+type Media struct {
+	ID           string         `json:"id" gorm:"type:char(36);primaryKey"`
+	Filename     string         `json:"filename" gorm:"type:varchar(255);not null;index"`
+	URL          string         `json:"url" gorm:"type:varchar(500);not null"`
+	MimeType     string         `json:"mime_type" gorm:"type:varchar(100);not null"`
+	Size         int64          `json:"size"`
+	Width        int            `json:"width"`
+	Height       int            `json:"height"`
+	Variants     []ImageVariant `json:"variants" gorm:"serializer:json;type:text"`
+	Alt          string         `json:"alt" gorm:"type:varchar(500)"`
+	UploadedByID *string        `json:"uploaded_by_id" gorm:"type:char(36)"`
+	RefCount     int64          `json:"ref_count" gorm:"-"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+func (m *Media) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -8,15 +8,19 @@ import (
 )
 
 type Project struct {
-	ID          string     `json:"id" gorm:"type:char(36);primaryKey"`
-	Title       string     `json:"title" gorm:"type:varchar(255);not null"`
-	Description string     `json:"description" gorm:"type:text;not null"`
-	Image       string     `json:"image" gorm:"type:varchar(500)"`
-	Link        string     `json:"link" gorm:"type:varchar(500)"`
-	Languages   []Language `json:"languages" gorm:"many2many:project_languages;"`
-	Authors     []User     `json:"authors" gorm:"many2many:project_authors;"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID           string     `json:"id" gorm:"type:char(36);primaryKey"`
+	Title        string     `json:"title" gorm:"type:varchar(255);not null"`
+	Description  string     `json:"description" gorm:"type:text;not null"`
+	Image        string     `json:"image" gorm:"type:varchar(500)"`
+	ImageMediaID *string    `json:"image_media_id" gorm:"type:char(36);index"`
+	ImageMedia   *Media     `json:"-" gorm:"foreignKey:ImageMediaID"`
+	Images       *ImageSet  `json:"images,omitempty" gorm:"-"`
+	Link         string     `json:"link" gorm:"type:varchar(500)"`
+	Languages    []Language `json:"languages" gorm:"many2many:project_languages;"`
+	Authors      []User     `json:"authors" gorm:"many2many:project_authors;"`
+	Tags         []Tag      `json:"tags" gorm:"many2many:project_tags;"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
 func (p *Project) BeforeCreate(tx *gorm.DB) error {
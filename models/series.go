@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Series fasst mehrteilige Blogs zusammen. Die Reihenfolge steht in
+// Blog.SeriesPosition.
+type Series struct {
+	ID          string    `json:"id" gorm:"type:char(36);primaryKey"`
+	Title       string    `json:"title" gorm:"type:varchar(255);not null"`
+	Slug        string    `json:"slug" gorm:"type:varchar(255);uniqueIndex;not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	Blogs       []Blog    `json:"blogs,omitempty" gorm:"foreignKey:SeriesID"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (s *Series) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// SeriesPart verweist auf einen anderen Teil einer Serie.
+type SeriesPart struct {
+	ID       uint   `json:"id"`
+	Title    string `json:"title"`
+	Slug     string `json:"slug"`
+	Position int    `json:"position"`
+}
+
+// SeriesInfo steht in der Antwort eines Blogs, der zu einer Serie gehört.
+type SeriesInfo struct {
+	ID          string       `json:"id"`
+	Title       string       `json:"title"`
+	Slug        string       `json:"slug"`
+	Description string       `json:"description"`
+	Position    int          `json:"position"`
+	Total       int          `json:"total"`
+	Previous    *SeriesPart  `json:"previous"`
+	Next        *SeriesPart  `json:"next"`
+	Parts       []SeriesPart `json:"parts"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Session struct {
+	ID               string    `json:"id" gorm:"type:char(36);primaryKey"`
+	TokenHash        string    `json:"-" gorm:"type:char(64);uniqueIndex;not null"`
+	RefreshHash      string    `json:"-" gorm:"type:char(64);uniqueIndex;not null"`
+	Subject          string    `json:"subject" gorm:"type:varchar(255);not null"`
+	UserID           *string   `json:"user_id" gorm:"type:char(36);index"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
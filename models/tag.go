@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Tag struct {
+	ID           string    `json:"id" gorm:"type:char(36);primaryKey"`
+	Name         string    `json:"name" gorm:"type:varchar(100);not null"`
+	Slug         string    `json:"slug" gorm:"type:varchar(100);uniqueIndex;not null"`
+	BlogCount    int64     `json:"blog_count" gorm:"-"`
+	ProjectCount int64     `json:"project_count" gorm:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -7,19 +7,35 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleAuthor = "author"
+)
+
 type User struct {
-	ID        string    `json:"id" gorm:"type:char(36);primaryKey"`
-	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
-	Email     *string   `json:"email" gorm:"type:varchar(255);uniqueIndex"`
-	Avatar    string    `json:"avatar" gorm:"type:varchar(500)"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Blogs     []Blog    `json:"blogs,omitempty" gorm:"many2many:blog_authors;"`
+	ID            string    `json:"id" gorm:"type:char(36);primaryKey"`
+	Name          string    `json:"name" gorm:"type:varchar(255);not null"`
+	Email         *string   `json:"email" gorm:"type:varchar(255);uniqueIndex"`
+	Avatar        string    `json:"avatar" gorm:"type:varchar(500)"`
+	AvatarMediaID *string   `json:"avatar_media_id" gorm:"type:char(36);index"`
+	Role          string    `json:"role" gorm:"type:varchar(20);not null;default:author"`
+	PasswordHash  string    `json:"-" gorm:"type:varchar(255)"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Blogs         []Blog    `json:"blogs,omitempty" gorm:"many2many:blog_authors;"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == "" {
 		u.ID = uuid.New().String()
 	}
+	if u.Role == "" {
+		u.Role = RoleAuthor
+	}
 	return nil
 }
+
+func IsValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleEditor || role == RoleAuthor
+}
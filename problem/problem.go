@@ -0,0 +1,223 @@
+// Package problem beantwortet Fehler einheitlich als application/problem+json
+// nach RFC 7807.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const ContentType = "application/problem+json"
+
+// RequestIDKey ist der Schlüssel im gin.Context, unter dem
+// middleware.RequestID die ID der Anfrage ablegt.
+const RequestIDKey = "request_id"
+
+// Maschinenlesbare Fehlercodes
+const (
+	CodeBadRequest        = "bad_request"
+	CodeValidation        = "validation_failed"
+	CodeInvalidQuery      = "invalid_query"
+	CodeUnauthorized      = "unauthorized"
+	CodeInvalidCredential = "invalid_credentials"
+	CodeForbidden         = "forbidden"
+	CodeNotFound          = "not_found"
+	CodeMethodNotAllowed  = "method_not_allowed"
+	CodeConflict          = "conflict"
+	CodePayloadTooLarge   = "payload_too_large"
+	CodeUnsupportedMedia  = "unsupported_media_type"
+	CodeRateLimited       = "rate_limited"
+	CodeInternal          = "internal_error"
+	CodeDatabase          = "database_error"
+	CodeStorage           = "storage_error"
+)
+
+// Codes von Feldfehlern
+const (
+	FieldRequired = "required"
+	FieldInvalid  = "invalid"
+	FieldTooLong  = "too_long"
+	FieldTaken    = "taken"
+)
+
+// FieldError beschreibt, was an einem einzelnen Eingabefeld falsch ist.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Problem ist der Body einer Fehlerantwort. Error enthält denselben Text wie
+// Detail, damit Clients, die nur "error" lesen, weiter funktionieren.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Error     string       `json:"error"`
+
+	// Extensions sind weitere Felder auf oberster Ebene, z.B. ref_count
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON schreibt die Extensions neben die Standardfelder.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	data, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range p.Extensions {
+		if _, exists := fields[key]; !exists {
+			fields[key] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// typeURI kommt aus PROBLEM_TYPE_BASE (z.B. "https://api.example.com/problems/"),
+// an die der Code angehängt wird. Ohne ist der Typ "about:blank".
+func typeURI(code string) string {
+	if base := os.Getenv("PROBLEM_TYPE_BASE"); base != "" {
+		return strings.TrimSuffix(base, "/") + "/" + code
+	}
+	return "about:blank"
+}
+
+// codeForStatus liefert den Code für Fehler ohne eigenen Code.
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMedia
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	}
+	return CodeInternal
+}
+
+// Write schreibt das Problem und bricht die Anfrage ab.
+func Write(c *gin.Context, p Problem) {
+	if p.Code == "" {
+		p.Code = codeForStatus(p.Status)
+	}
+	if p.Type == "" {
+		p.Type = typeURI(p.Code)
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	p.RequestID = c.GetString(RequestIDKey)
+	p.Error = p.Detail
+
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// Abort antwortet mit status und einer Beschreibung für Menschen. Der Code
+// ergibt sich aus dem Status.
+func Abort(c *gin.Context, status int, detail string) {
+	Write(c, Problem{Status: status, Detail: detail})
+}
+
+// AbortCode antwortet wie Abort, aber mit einem eigenen Code.
+func AbortCode(c *gin.Context, status int, code, detail string) {
+	Write(c, Problem{Status: status, Code: code, Detail: detail})
+}
+
+// Invalid antwortet mit 400 und den Fehlern einzelner Felder.
+func Invalid(c *gin.Context, fields ...FieldError) {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	Write(c, Problem{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidation,
+		Detail: strings.Join(messages, "; "),
+		Errors: fields,
+	})
+}
+
+// Field ist eine Abkürzung für Invalid mit genau einem Feld.
+func Field(c *gin.Context, field, code, message string) {
+	Invalid(c, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Taken antwortet mit 409, weil der Wert eines Feldes schon vergeben ist.
+func Taken(c *gin.Context, field, message string) {
+	Write(c, Problem{
+		Status: http.StatusConflict,
+		Detail: message,
+		Errors: []FieldError{{Field: field, Code: FieldTaken, Message: message}},
+	})
+}
+
+// Internal protokolliert err und antwortet mit 500. Die Ursache bleibt im
+// Log, der Client sieht nur detail und die Request ID.
+func Internal(c *gin.Context, code string, err error, detail string) {
+	log.Printf("[%s] %s %s: %s: %v", c.GetString(RequestIDKey), c.Request.Method, c.Request.URL.Path, detail, err)
+	Write(c, Problem{Status: http.StatusInternalServerError, Code: code, Detail: detail})
+}
+
+// Database beantwortet einen Datenbankfehler. Verletzte Unique-Indizes
+// werden zu 409, alles andere zu 500.
+func Database(c *gin.Context, err error, detail string) {
+	if IsDuplicate(err) {
+		Abort(c, http.StatusConflict, detail)
+		return
+	}
+	Internal(c, CodeDatabase, err, detail)
+}
+
+// Lookup beantwortet den Fehler beim Laden eines einzelnen Eintrags: 404,
+// wenn es ihn nicht gibt, sonst 500.
+func Lookup(c *gin.Context, err error, notFound string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		Abort(c, http.StatusNotFound, notFound)
+		return
+	}
+	Internal(c, CodeDatabase, err, "Failed to load "+strings.ToLower(strings.TrimSuffix(notFound, " not found")))
+}
+
+// IsDuplicate erkennt Verletzungen eines Unique-Index bei MySQL und SQLite.
+func IsDuplicate(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return true
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey) ||
+		strings.Contains(err.Error(), "Duplicate entry") || strings.Contains(err.Error(), "UNIQUE constraint")
+}
@@ -0,0 +1,146 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	log.SetOutput(io.Discard)
+}
+
+// respond ruft write mit einem Test-Context auf und liefert Status und Body.
+func respond(t *testing.T, write func(c *gin.Context)) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/blogs/1", nil)
+	c.Set(RequestIDKey, "req-1")
+	write(c)
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", w.Body.String(), err)
+	}
+	return w, body
+}
+
+func TestResponses(t *testing.T) {
+	duplicate := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a' for key 'slug'"}
+	tests := []struct {
+		name   string
+		write  func(c *gin.Context)
+		status int
+		code   string
+		detail string
+	}{
+		{"abort", func(c *gin.Context) { Abort(c, http.StatusForbidden, "No access") }, 403, CodeForbidden, "No access"},
+		{"abort unknown status", func(c *gin.Context) { Abort(c, http.StatusTeapot, "Tea") }, 418, CodeInternal, "Tea"},
+		{"abort code", func(c *gin.Context) { AbortCode(c, 400, CodeInvalidQuery, "Bad sort") }, 400, CodeInvalidQuery, "Bad sort"},
+		{"field", func(c *gin.Context) { Field(c, "title", FieldRequired, "Title is required") }, 400, CodeValidation, "Title is required"},
+		{"taken", func(c *gin.Context) { Taken(c, "slug", "Slug is taken") }, 409, CodeConflict, "Slug is taken"},
+		{"internal", func(c *gin.Context) { Internal(c, CodeStorage, errors.New("disk full"), "Failed to save") }, 500, CodeStorage, "Failed to save"},
+		{"database duplicate", func(c *gin.Context) { Database(c, duplicate, "Failed to save") }, 409, CodeConflict, "Failed to save"},
+		{"database other", func(c *gin.Context) { Database(c, errors.New("gone"), "Failed to save") }, 500, CodeDatabase, "Failed to save"},
+		{"lookup not found", func(c *gin.Context) { Lookup(c, gorm.ErrRecordNotFound, "Blog not found") }, 404, CodeNotFound, "Blog not found"},
+		{"lookup wrapped not found", func(c *gin.Context) {
+			Lookup(c, fmt.Errorf("load: %w", gorm.ErrRecordNotFound), "Blog not found")
+		}, 404, CodeNotFound, "Blog not found"},
+		{"lookup error", func(c *gin.Context) { Lookup(c, errors.New("gone"), "Blog not found") }, 500, CodeDatabase, "Failed to load blog"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, body := respond(t, tt.write)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Content-Type"); got != ContentType {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if body["code"] != tt.code {
+				t.Errorf("code = %v, want %s", body["code"], tt.code)
+			}
+			if body["detail"] != tt.detail || body["error"] != tt.detail {
+				t.Errorf("detail = %v, error = %v, want %s", body["detail"], body["error"], tt.detail)
+			}
+			if body["status"] != float64(tt.status) {
+				t.Errorf("body status = %v", body["status"])
+			}
+			if body["instance"] != "/blogs/1" || body["request_id"] != "req-1" || body["type"] != "about:blank" {
+				t.Errorf("unexpected standard fields: %v", body)
+			}
+		})
+	}
+}
+
+func TestInvalidCollectsFields(t *testing.T) {
+	_, body := respond(t, func(c *gin.Context) {
+		Invalid(c,
+			FieldError{Field: "title", Code: FieldRequired, Message: "Title is required"},
+			FieldError{Field: "name", Code: FieldTooLong, Message: "Name is too long"})
+	})
+	if body["detail"] != "Title is required; Name is too long" {
+		t.Errorf("detail = %v", body["detail"])
+	}
+	errs, _ := body["errors"].([]any)
+	if len(errs) != 2 {
+		t.Fatalf("errors = %v", body["errors"])
+	}
+	if first := errs[0].(map[string]any); first["field"] != "title" || first["code"] != FieldRequired {
+		t.Errorf("first error = %v", first)
+	}
+}
+
+func TestTypeURIFromEnv(t *testing.T) {
+	t.Setenv("PROBLEM_TYPE_BASE", "https://api.example.com/problems/")
+	_, body := respond(t, func(c *gin.Context) { Abort(c, http.StatusNotFound, "Missing") })
+	if body["type"] != "https://api.example.com/problems/not_found" {
+		t.Errorf("type = %v", body["type"])
+	}
+}
+
+func TestExtensions(t *testing.T) {
+	_, body := respond(t, func(c *gin.Context) {
+		Write(c, Problem{
+			Status:     http.StatusConflict,
+			Detail:     "In use",
+			Extensions: map[string]any{"ref_count": 2, "code": "overridden"},
+		})
+	})
+	if body["ref_count"] != float64(2) {
+		t.Errorf("ref_count = %v", body["ref_count"])
+	}
+	if body["code"] != CodeConflict {
+		t.Errorf("extension must not override code, got %v", body["code"])
+	}
+}
+
+func TestIsDuplicate(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&mysql.MySQLError{Number: 1062}, true},
+		{fmt.Errorf("save: %w", &mysql.MySQLError{Number: 1062}), true},
+		{&mysql.MySQLError{Number: 1045}, false},
+		{gorm.ErrDuplicatedKey, true},
+		{errors.New("UNIQUE constraint failed: tags.slug"), true},
+		{errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		if got := IsDuplicate(tt.err); got != tt.want {
+			t.Errorf("IsDuplicate(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"PortfolioAPI/database"
+	"PortfolioAPI/models"
+)
+
+// StartBlogPublisher stellt regelmäßig alle fälligen geplanten Blogs auf
+// published um. Das Intervall kommt aus BLOG_SCHEDULER_INTERVAL (z.B. "30s"),
+// Standard ist eine Minute.
+func StartBlogPublisher() {
+	interval := time.Minute
+	if value := os.Getenv("BLOG_SCHEDULER_INTERVAL"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			interval = d
+		}
+	}
+
+	go func() {
+		publishDueBlogs()
+		for range time.Tick(interval) {
+			publishDueBlogs()
+		}
+	}()
+}
+
+func publishDueBlogs() {
+	result := database.DB.Model(&models.Blog{}).
+		Where("status = ? AND published_at <= ?", models.BlogStatusScheduled, time.Now()).
+		Update("status", models.BlogStatusPublished)
+	if result.Error != nil {
+		log.Printf("scheduler: failed to publish scheduled blogs: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("scheduler: published %d scheduled blog(s)", result.RowsAffected)
+	}
+}
@@ -0,0 +1,151 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"PortfolioAPI/slug"
+)
+
+// Hit ist ein Suchtreffer mit seiner Relevanz.
+type Hit struct {
+	Type  string
+	ID    string
+	Score float64
+}
+
+// Terms zerlegt einen Text in normalisierte Suchbegriffe. Umlaute werden wie
+// bei Slugs ausgeschrieben, damit "Über" auch "ueber" findet.
+func Terms(text string) []string {
+	terms := []string{}
+	for _, word := range words(text) {
+		if term := slug.Make(word.text); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func uniqueTerms(text string) []string {
+	seen := map[string]bool{}
+	terms := []string{}
+	for _, term := range Terms(text) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+type word struct {
+	text       string
+	start, end int
+}
+
+// words liefert die Wörter eines Textes mit ihren Byte-Positionen.
+func words(text string) []word {
+	result := []word{}
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			result = append(result, word{text[start:i], start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, word{text[start:], start, len(text)})
+	}
+	return result
+}
+
+func queryTerms(query string) map[string]bool {
+	terms := map[string]bool{}
+	for _, term := range uniqueTerms(query) {
+		terms[term] = true
+	}
+	return terms
+}
+
+// Highlight markiert alle Treffer im ganzen Text mit <mark>, ohne ihn zu
+// kürzen. Gedacht für kurze Texte wie Titel.
+func Highlight(text, query string) string {
+	return mark(text, words(text), queryTerms(query), 0, len(text))
+}
+
+// Snippet schneidet einen Ausschnitt um den ersten Treffer aus und markiert
+// alle Treffer mit <mark>. Der restliche Text ist HTML-escaped.
+func Snippet(text, query string, length int) string {
+	terms := queryTerms(query)
+	all := words(text)
+	first := -1
+	for _, w := range all {
+		if terms[slug.Make(w.text)] {
+			first = w.start
+			break
+		}
+	}
+
+	// Fenster um den ersten Treffer, an Wortgrenzen ausgerichtet
+	start, end := 0, len(text)
+	if first >= 0 {
+		start = first - length/3
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start+length < end {
+		end = start + length
+	}
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	aligned, alignedEnd := start, end
+	for _, w := range all {
+		if w.start < start && w.end > start {
+			aligned = w.start
+		}
+		if w.start < end && w.end > end {
+			alignedEnd = w.start
+		}
+	}
+	// Ein einzelnes Wort länger als das Fenster: hart abschneiden
+	if alignedEnd > aligned {
+		start, end = aligned, alignedEnd
+	}
+
+	return mark(text, all, terms, start, end)
+}
+
+// mark gibt text[start:end] HTML-escaped zurück, Treffer in <mark>. Fehlt
+// Text am Anfang oder Ende, steht dort "…".
+func mark(text string, all []word, terms map[string]bool, start, end int) string {
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, w := range all {
+		if w.start < start || w.end > end || !terms[slug.Make(w.text)] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:w.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(w.text))
+		b.WriteString("</mark>")
+		pos = w.end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
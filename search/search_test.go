@@ -0,0 +1,86 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Hello, World!", []string{"hello", "world"}},
+		{"Über Straßen", []string{"ueber", "strassen"}},
+		{"Go 1.24", []string{"go", "1", "24"}},
+		{"  ", []string{}},
+	}
+	for _, tt := range tests {
+		if got := Terms(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text, query, want string
+	}{
+		{"Docker and Go", "go", "Docker and <mark>Go</mark>"},
+		{"Über <Go>", "ueber", "<mark>Über</mark> &lt;Go&gt;"},
+		{"Nothing here", "go", "Nothing here"},
+	}
+	for _, tt := range tests {
+		if got := Highlight(tt.text, tt.query); got != tt.want {
+			t.Errorf("Highlight(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("filler ", 50) + "target word " + strings.Repeat("more ", 50)
+	tests := []struct {
+		name     string
+		text     string
+		query    string
+		length   int
+		contains string
+		prefix   bool
+		suffix   bool
+	}{
+		{"short text", "A short text about Go", "go", 200, "<mark>Go</mark>", false, false},
+		{"window around hit", long, "target", 60, "<mark>target</mark>", true, true},
+		{"no hit starts at beginning", long, "missing", 30, "filler", false, true},
+		{"single long token", strings.Repeat("x", 500), "missing", 50, strings.Repeat("x", 50), false, true},
+		{"long token after hit", "go " + strings.Repeat("y", 500), "go", 30, "<mark>go</mark>", false, true},
+		{"escapes html", "<b>go</b>", "go", 200, "&lt;b&gt;<mark>go</mark>&lt;/b&gt;", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Snippet(tt.text, tt.query, tt.length)
+			if got == "" {
+				t.Fatal("empty snippet")
+			}
+			if !strings.Contains(got, tt.contains) {
+				t.Errorf("snippet %q does not contain %q", got, tt.contains)
+			}
+			if strings.HasPrefix(got, "…") != tt.prefix {
+				t.Errorf("snippet %q: leading ellipsis = %v, want %v", got, !tt.prefix, tt.prefix)
+			}
+			if strings.HasSuffix(got, "…") != tt.suffix {
+				t.Errorf("snippet %q: trailing ellipsis = %v, want %v", got, !tt.suffix, tt.suffix)
+			}
+		})
+	}
+}
+
+func TestSnippetMultibyteLongToken(t *testing.T) {
+	got := Snippet(strings.Repeat("ä", 300), "missing", 51)
+	if got == "" || !strings.HasSuffix(got, "…") {
+		t.Fatalf("snippet = %q", got)
+	}
+	if strings.ContainsRune(got, '�') {
+		t.Errorf("snippet cuts a rune in half: %q", got)
+	}
+}
@@ -0,0 +1,56 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Deutsche Sonderzeichen werden ausgeschrieben statt nur entfernt
+var replacements = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue",
+	"Ä", "ae", "Ö", "oe", "Ü", "ue",
+	"ß", "ss", "ẞ", "ss",
+)
+
+// MaxLength ist die Höchstlänge eines Slugs. Bis zur Spaltenlänge von 255
+// bleibt Platz für eine angehängte Nummer wie "-2".
+const MaxLength = 240
+
+// Make erzeugt aus einem Text einen URL-Slug aus Kleinbuchstaben, Ziffern
+// und Bindestrichen. Andere Akzente werden entfernt ("Café" wird "cafe").
+// Längere Slugs werden an einer Wortgrenze auf MaxLength gekürzt.
+func Make(s string) string {
+	s = norm.NFD.String(replacements.Replace(norm.NFC.String(s)))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Akzent nach der Zerlegung, einfach weglassen
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return truncate(b.String())
+}
+
+// truncate kürzt einen Slug auf MaxLength, möglichst am letzten Bindestrich.
+func truncate(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+	s = s[:MaxLength+1]
+	if i := strings.LastIndexByte(s, '-'); i > 0 {
+		return s[:i]
+	}
+	return s[:MaxLength]
+}
@@ -0,0 +1,51 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello World", "hello-world"},
+		{"  Leading and trailing  ", "leading-and-trailing"},
+		{"Über größere Straßen", "ueber-groessere-strassen"},
+		{"ÄÖÜ äöü ß ẞ", "aeoeue-aeoeue-ss-ss"},
+		{"Café crème", "cafe-creme"},
+		{"Go 1.24: what's new?", "go-1-24-what-s-new"},
+		{"multiple---dashes___here", "multiple-dashes-here"},
+		{"日本語", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.in); got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMakeTruncates(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"at word boundary", strings.Repeat("wort ", 100), 239},
+		{"umlauts expand", strings.Repeat("ä", 200), MaxLength},
+		{"single long word", strings.Repeat("a", 300), MaxLength},
+		{"exact length", strings.Repeat("a", MaxLength), MaxLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Make(tt.in)
+			if len(got) != tt.want {
+				t.Errorf("len = %d, want %d", len(got), tt.want)
+			}
+			if strings.HasSuffix(got, "-") {
+				t.Errorf("slug ends with a dash: %q", got)
+			}
+		})
+	}
+}
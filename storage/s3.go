@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3 speichert Dateien in einem S3-kompatiblen Bucket (AWS, MinIO, ...).
+type S3 struct {
+	client *minio.Client
+	bucket string
+}
+
+// publicReadPolicy erlaubt anonymes Lesen aller Objekte, damit die Links
+// über CDN_URL ohne Signatur funktionieren.
+const publicReadPolicy = `{
+	"Version": "2012-10-17",
+	"Statement": [{
+		"Effect": "Allow",
+		"Principal": {"AWS": ["*"]},
+		"Action": ["s3:GetObject"],
+		"Resource": ["arn:aws:s3:::%s/*"]
+	}]
+}`
+
+// NewS3FromEnv liest S3_ENDPOINT, S3_ACCESS_KEY, S3_SECRET_KEY, S3_BUCKET,
+// S3_REGION und S3_USE_SSL. Für einen lokalen MinIO z.B.
+// S3_ENDPOINT=localhost:9000 und S3_USE_SSL=false.
+//
+// Fehlt der Bucket, wird er angelegt und bekommt eine Policy für anonymes
+// Lesen. Ein bestehender Bucket muss selbst öffentlich lesbar sein (bei AWS
+// ohne "Block Public Access"), sonst liefern die Links über CDN_URL 403.
+// Mit S3_PUBLIC_READ=false bleibt ein neuer Bucket privat, etwa wenn ein CDN
+// mit eigenem Zugriff davor sitzt.
+func NewS3FromEnv() (*S3, error) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "s3.amazonaws.com"
+	}
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" {
+		return nil, errors.New("S3_BUCKET is required")
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(os.Getenv("S3_ACCESS_KEY"), os.Getenv("S3_SECRET_KEY"), ""),
+		Secure: os.Getenv("S3_USE_SSL") != "false",
+		Region: os.Getenv("S3_REGION"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: os.Getenv("S3_REGION")}); err != nil {
+			return nil, err
+		}
+		if os.Getenv("S3_PUBLIC_READ") != "false" {
+			if err := client.SetBucketPolicy(ctx, bucket, fmt.Sprintf(publicReadPolicy, bucket)); err != nil {
+				return nil, fmt.Errorf("set public read policy: %w", err)
+			}
+		}
+	}
+
+	return &S3{client: client, bucket: bucket}, nil
+}
+
+func (s *S3) Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, strings.TrimPrefix(key, "/"), r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.client.GetObject(ctx, s.bucket, strings.TrimPrefix(key, "/"), minio.GetObjectOptions{})
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, strings.TrimPrefix(key, "/"), minio.RemoveObjectOptions{})
+}
+
+func (s *S3) DeletePrefix(ctx context.Context, prefix string) error {
+	prefix = strings.TrimSuffix(strings.TrimPrefix(prefix, "/"), "/") + "/"
+	objects := s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true})
+	for err := range s.client.RemoveObjects(ctx, s.bucket, objects, minio.RemoveObjectsOptions{}) {
+		if err.Err != nil {
+			return err.Err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Läuft nur gegen einen echten S3-kompatiblen Server, z.B. einen lokalen MinIO:
+//
+//	S3_ENDPOINT=localhost:9000 S3_ACCESS_KEY=minioadmin S3_SECRET_KEY=minioadmin \
+//	S3_USE_SSL=false go test ./storage
+//
+// Ohne S3_BUCKET wird ein eigener Bucket angelegt und danach wieder entfernt.
+func newTestS3(t *testing.T) *S3 {
+	t.Helper()
+	if os.Getenv("S3_ENDPOINT") == "" {
+		t.Skip("S3_ENDPOINT is not set")
+	}
+
+	ownBucket := os.Getenv("S3_BUCKET") == ""
+	if ownBucket {
+		t.Setenv("S3_BUCKET", fmt.Sprintf("portfolio-test-%d", time.Now().UnixNano()))
+	}
+
+	s, err := NewS3FromEnv()
+	if err != nil {
+		t.Fatalf("NewS3FromEnv: %v", err)
+	}
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		if err := s.DeletePrefix(ctx, "test"); err != nil {
+			t.Errorf("cleanup: %v", err)
+		}
+		if ownBucket {
+			if err := s.client.RemoveBucket(ctx, s.bucket); err != nil {
+				t.Errorf("remove bucket: %v", err)
+			}
+		}
+	})
+	return s
+}
+
+func TestS3SaveOpenDelete(t *testing.T) {
+	s := newTestS3(t)
+	ctx := context.Background()
+
+	content := "hello storage"
+	if err := s.Save(ctx, "/test/a/file.txt", strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	r, err := s.Open(ctx, "test/a/file.txt")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != content {
+		t.Fatalf("content = %q, want %q", data, content)
+	}
+
+	info, err := s.client.StatObject(ctx, s.bucket, "test/a/file.txt", minio.StatObjectOptions{})
+	if err != nil {
+		t.Fatalf("StatObject: %v", err)
+	}
+	if info.ContentType != "text/plain" {
+		t.Errorf("content type = %q, want text/plain", info.ContentType)
+	}
+
+	if err := s.Delete(ctx, "/test/a/file.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.client.StatObject(ctx, s.bucket, "test/a/file.txt", minio.StatObjectOptions{}); err == nil {
+		t.Fatal("object still exists after Delete")
+	}
+}
+
+func TestS3DeletePrefix(t *testing.T) {
+	s := newTestS3(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"test/blog/1.png", "test/blog/sub/2.png", "test/blogs/3.png"} {
+		if err := s.Save(ctx, key, strings.NewReader("x"), 1, "image/png"); err != nil {
+			t.Fatalf("Save %s: %v", key, err)
+		}
+	}
+
+	if err := s.DeletePrefix(ctx, "/test/blog/"); err != nil {
+		t.Fatalf("DeletePrefix: %v", err)
+	}
+
+	var keys []string
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: "test/", Recursive: true}) {
+		if object.Err != nil {
+			t.Fatalf("ListObjects: %v", object.Err)
+		}
+		keys = append(keys, object.Key)
+	}
+	// "test/blogs" teilt nur den Präfix-Text und muss bleiben
+	if len(keys) != 1 || keys[0] != "test/blogs/3.png" {
+		t.Fatalf("remaining keys = %v, want [test/blogs/3.png]", keys)
+	}
+}
+
+func TestS3PublicRead(t *testing.T) {
+	if os.Getenv("S3_BUCKET") != "" {
+		t.Skip("public read policy is only set on buckets created by the API")
+	}
+	s := newTestS3(t)
+	ctx := context.Background()
+
+	if err := s.Save(ctx, "test/public.txt", strings.NewReader("public"), 6, "text/plain"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	scheme := "https"
+	if os.Getenv("S3_USE_SSL") == "false" {
+		scheme = "http"
+	}
+	resp, err := http.Get(fmt.Sprintf("%s://%s/%s/test/public.txt", scheme, os.Getenv("S3_ENDPOINT"), s.bucket))
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("anonymous GET status = %d, want 200", resp.StatusCode)
+	}
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// Storage speichert hochgeladene Dateien. Keys sind relative Pfade wie
+// "media/<id>/original.png" und werden über CDN_URL öffentlich erreichbar.
+type Storage interface {
+	Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix löscht alle Dateien unterhalb von prefix (wie ein Ordner).
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+var Store Storage
+
+// Init wählt das Backend anhand von STORAGE_DRIVER ("local" oder "s3").
+func Init() {
+	driver := os.Getenv("STORAGE_DRIVER")
+	if driver == "" {
+		driver = "local"
+	}
+
+	switch driver {
+	case "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "public"
+		}
+		local, err := NewLocal(dir)
+		if err != nil {
+			panic("Failed to initialize local storage: " + err.Error())
+		}
+		Store = local
+	case "s3":
+		s3, err := NewS3FromEnv()
+		if err != nil {
+			panic("Failed to initialize S3 storage: " + err.Error())
+		}
+		Store = s3
+	default:
+		panic("Unknown STORAGE_DRIVER: " + driver)
+	}
+}
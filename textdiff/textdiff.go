@@ -0,0 +1,187 @@
+package textdiff
+
+import "strings"
+
+type Op string
+
+const (
+	Equal  Op = " "
+	Insert Op = "+"
+	Delete Op = "-"
+)
+
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines vergleicht zwei Texte zeilenweise (Myers-Algorithmus) und liefert
+// die Zeilen in Reihenfolge, jeweils markiert als gleich, neu oder entfernt.
+func Lines(a, b string) []Line {
+	return diff(splitLines(a), splitLines(b))
+}
+
+// Changed gibt an, ob ein Diff mindestens eine Änderung enthält.
+func Changed(lines []Line) bool {
+	for _, line := range lines {
+		if line.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// maxCost begrenzt die Zahl der Schritte d bei der Suche nach der mittleren
+// Schlange. Darüber wird der Abschnitt als komplett ersetzt ausgegeben,
+// damit sehr unterschiedliche Texte nicht beliebig viel Zeit kosten.
+const maxCost = 1000
+
+func diff(a, b []string) []Line {
+	var out []Line
+	compare(a, b, &out)
+	return out
+}
+
+// compare hängt das Diff von a und b an out an. Gemeinsamer Anfang und
+// gemeinsames Ende werden abgeschnitten, der Rest wird an der mittleren
+// Schlange geteilt (Myers in linearem Speicher).
+func compare(a, b []string, out *[]Line) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for _, line := range a[:prefix] {
+		*out = append(*out, Line{Equal, line})
+	}
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	if len(a) == 0 || len(b) == 0 {
+		replace(a, b, out)
+	} else if x, y, ok := middleSnake(a, b); ok {
+		compare(a[:x], b[:y], out)
+		compare(a[x:], b[y:], out)
+	} else {
+		replace(a, b, out)
+	}
+
+	for _, line := range common {
+		*out = append(*out, Line{Equal, line})
+	}
+}
+
+// replace gibt a als entfernt und b als neu aus.
+func replace(a, b []string, out *[]Line) {
+	for _, line := range a {
+		*out = append(*out, Line{Delete, line})
+	}
+	for _, line := range b {
+		*out = append(*out, Line{Insert, line})
+	}
+}
+
+// middleSnake sucht gleichzeitig vom Anfang und vom Ende, bis sich beide
+// Pfade treffen, und liefert den Punkt, an dem das Problem geteilt wird.
+// v1[k+offset] ist das weiteste x auf Diagonale k von vorne, v2 dasselbe von
+// hinten. ok ist false, wenn maxCost erreicht wurde.
+func middleSnake(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	size := 2*maxD + 3
+	v1 := make([]int, size)
+	v2 := make([]int, size)
+	for i := range v1 {
+		v1[i] = -1
+		v2[i] = -1
+	}
+	v1[offset+1] = 0
+	v2[offset+1] = 0
+
+	delta := n - m
+	// Bei ungerader Differenz treffen sich die Pfade beim Schritt von vorne
+	front := delta%2 != 0
+	// Diagonalen, die über den Rand hinausgelaufen sind, werden übersprungen
+	k1Start, k1End, k2Start, k2End := 0, 0, 0, 0
+
+	for d := 0; d < maxD && d <= maxCost; d++ {
+		for k1 := -d + k1Start; k1 <= d-k1End; k1 += 2 {
+			i := offset + k1
+			var x1 int
+			if k1 == -d || (k1 != d && v1[i-1] < v1[i+1]) {
+				x1 = v1[i+1]
+			} else {
+				x1 = v1[i-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			v1[i] = x1
+			if x1 > n {
+				k1End += 2
+			} else if y1 > m {
+				k1Start += 2
+			} else if front {
+				j := offset + delta - k1
+				if j >= 0 && j < size && v2[j] != -1 && x1 >= n-v2[j] {
+					return split(x1, y1, n, m)
+				}
+			}
+		}
+
+		for k2 := -d + k2Start; k2 <= d-k2End; k2 += 2 {
+			i := offset + k2
+			var x2 int
+			if k2 == -d || (k2 != d && v2[i-1] < v2[i+1]) {
+				x2 = v2[i+1]
+			} else {
+				x2 = v2[i-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			v2[i] = x2
+			if x2 > n {
+				k2End += 2
+			} else if y2 > m {
+				k2Start += 2
+			} else if !front {
+				j := offset + delta - k2
+				if j >= 0 && j < size && v1[j] != -1 {
+					x1 := v1[j]
+					y1 := x1 - (j - offset)
+					if x1 >= n-x2 {
+						return split(x1, y1, n, m)
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// split lehnt Teilungen ab, die das Problem nicht verkleinern.
+func split(x, y, n, m int) (int, int, bool) {
+	if (x == 0 && y == 0) || (x == n && y == m) {
+		return 0, 0, false
+	}
+	return x, y, true
+}
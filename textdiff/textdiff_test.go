@@ -0,0 +1,146 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rebuild setzt aus einem Diff die beiden Ausgangstexte wieder zusammen.
+func rebuild(lines []Line) (string, string) {
+	var a, b []string
+	for _, line := range lines {
+		if line.Op != Insert {
+			a = append(a, line.Text)
+		}
+		if line.Op != Delete {
+			b = append(b, line.Text)
+		}
+	}
+	return strings.Join(a, "\n"), strings.Join(b, "\n")
+}
+
+func countChanges(lines []Line) int {
+	n := 0
+	for _, line := range lines {
+		if line.Op != Equal {
+			n++
+		}
+	}
+	return n
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		changes int
+	}{
+		{"empty", "", "", 0},
+		{"equal", "a\nb\nc", "a\nb\nc", 0},
+		{"insert into empty", "", "a\nb", 2},
+		{"delete all", "a\nb", "", 2},
+		{"insert middle", "a\nc", "a\nb\nc", 1},
+		{"delete middle", "a\nb\nc", "a\nc", 1},
+		{"replace line", "a\nb\nc", "a\nx\nc", 2},
+		{"crlf", "a\r\nb\r\n", "a\nb\n", 0},
+		{"classic", "a\nb\nc\na\nb\nb\na", "c\nb\na\nb\na\nc", 5},
+		{"completely different", "a\nb\nc", "x\ny", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Lines(tt.a, tt.b)
+			a, b := rebuild(lines)
+			if a != strings.Join(splitLines(tt.a), "\n") || b != strings.Join(splitLines(tt.b), "\n") {
+				t.Fatalf("diff does not rebuild inputs: got %q / %q", a, b)
+			}
+			if got := countChanges(lines); got != tt.changes {
+				t.Errorf("changes = %d, want %d (%v)", got, tt.changes, lines)
+			}
+			if Changed(lines) != (tt.changes > 0) {
+				t.Errorf("Changed = %v, want %v", Changed(lines), tt.changes > 0)
+			}
+		})
+	}
+}
+
+// Zwei große, völlig verschiedene Texte dürfen weder viel Speicher noch
+// viel Zeit kosten.
+func TestLinesLargeDifferentInputs(t *testing.T) {
+	var a, b strings.Builder
+	for i := 0; i < 32000; i++ {
+		fmt.Fprintf(&a, "a%d\n", i)
+		fmt.Fprintf(&b, "b%d\n", i)
+	}
+
+	start := time.Now()
+	lines := Lines(a.String(), b.String())
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("diff took %v", elapsed)
+	}
+	if got := countChanges(lines); got != 64000 {
+		t.Errorf("changes = %d, want 64000", got)
+	}
+	gotA, gotB := rebuild(lines)
+	if gotA+"\n" != a.String() || gotB+"\n" != b.String() {
+		t.Error("diff does not rebuild inputs")
+	}
+}
+
+func TestLinesLargeSimilarInputs(t *testing.T) {
+	var a, b strings.Builder
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&a, "line %d\n", i)
+		if i%1000 == 0 {
+			fmt.Fprintf(&b, "changed %d\n", i)
+		} else {
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+	}
+
+	lines := Lines(a.String(), b.String())
+	if got := countChanges(lines); got != 40 {
+		t.Errorf("changes = %d, want 40", got)
+	}
+}
+
+// lcs berechnet die Länge der längsten gemeinsamen Teilfolge zum Vergleich.
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// Kleine Eingaben liegen weit unter maxCost, das Diff muss also minimal sein.
+func TestLinesMinimal(t *testing.T) {
+	alphabet := []string{"a", "b", "c"}
+	seed := uint32(1)
+	random := func(n int) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			seed = seed*1664525 + 1013904223
+			lines[i] = alphabet[seed>>16%uint32(len(alphabet))]
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := random(i%13), random(i%7+i%5)
+		lines := Lines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+		want := len(a) + len(b) - 2*lcs(a, b)
+		if got := countChanges(lines); got != want {
+			t.Fatalf("%q -> %q: changes = %d, want %d", a, b, got, want)
+		}
+	}
+}